@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// AskOutput is the JSON output structure for the ask command
+type AskOutput struct {
+	RequestID  string     `json:"request_id"`
+	ShortID    string     `json:"short_id"`
+	Recipients []string   `json:"recipients"`
+	Response   ReadOutput `json:"response"`
+}
+
+var askCmd = &cobra.Command{
+	Use:   "ask <to> <subject> <body>",
+	Short: "Send a request and wait for the response",
+	Long: `Send a request-type message and block until a response arrives in
+its thread, then print the response body.
+
+The first response from any recipient is returned and marked as read.
+If none arrives before --timeout, exits with code 124; the request stays
+in the recipients' inboxes and can be waited on later with
+'amail wait --thread <id> --type response'.
+
+Examples:
+  amail ask dev "Schema?" "Which table holds sessions?"
+  amail ask pm "Approve deploy?" "Ready to ship v2" --timeout 30m
+  amail ask dev,qa -p high "Blocker?" "Anything blocking the release?"`,
+	Args: cobra.ExactArgs(3),
+	RunE: runAsk,
+}
+
+var (
+	askPriority string
+	askTimeout  string
+	askInterval int
+)
+
+func init() {
+	askCmd.Flags().StringVarP(&askPriority, "priority", "p", "normal", "Priority: low, normal, high, urgent")
+	askCmd.Flags().StringVar(&askTimeout, "timeout", "10m", "Give up after this long (e.g. 30s, 10m); 0 waits forever")
+	askCmd.Flags().IntVar(&askInterval, "interval", 0, "Fallback polling interval in seconds (default from config)")
+	rootCmd.AddCommand(askCmd)
+}
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	toArg := args[0]
+	subject := args[1]
+	body := args[2]
+
+	if err := validatePriority(askPriority); err != nil {
+		return err
+	}
+
+	var timeout time.Duration
+	if askTimeout != "0" {
+		d, err := parseDuration(askTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		timeout = d
+	}
+
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := useRules(database, cfg); err != nil {
+		return err
+	}
+
+	// Resolve sender identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
+	fromID := res.Identity
+
+	// Resolve recipients
+	recipients, err := resolveSendRecipients(toArg, fromID, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Send the request; it becomes the root of a new thread
+	request := &db.Message{
+		ID:        db.NewID(),
+		FromID:    fromID,
+		Subject:   subject,
+		Body:      body,
+		Priority:  askPriority,
+		MsgType:   "request",
+		CreatedAt: time.Now(),
+	}
+	if err := database.SendMessage(request, recipients); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// Wait for a response in the thread
+	filter := waitFilter{ThreadID: request.ID, MsgType: "response"}
+	response, err := waitForMessage(database, fromID, filter, timeout, pollInterval(cfg, askInterval))
+	if err != nil {
+		if ExitCode(err) == ExitTimeout {
+			return errTimeout("no response to %s from %s within %s",
+				SafeShortID(request.ID), strings.Join(recipients, ", "), timeout)
+		}
+		return err
+	}
+
+	// Mark as read
+	if err := database.MarkRead(response.ID, fromID); err != nil {
+		return fmt.Errorf("failed to mark as read: %w", err)
+	}
+	response.Status = "read"
+
+	// JSON output
+	if IsJSONOutput() {
+		output := AskOutput{
+			RequestID:  request.ID,
+			ShortID:    SafeShortID(request.ID),
+			Recipients: recipients,
+			Response:   toReadOutput(response),
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	fmt.Println(response.Body)
+
+	return nil
+}
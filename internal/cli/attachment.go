@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// AttachmentJSON is the JSON representation of a message attachment
+type AttachmentJSON struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+}
+
+// AttachmentSaveOutput is the JSON output structure for the attachment save command
+type AttachmentSaveOutput struct {
+	MessageID string `json:"message_id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+}
+
+// AttachmentGCOutput is the JSON output structure for the attachment gc command
+type AttachmentGCOutput struct {
+	Removed int `json:"removed"`
+}
+
+var attachmentCmd = &cobra.Command{
+	Use:   "attachment",
+	Short: "Work with message attachments",
+	Long: `Extract message attachments and clean up the blob store.
+
+Attachments are added with 'amail send --attach' and listed by 'amail read'.
+Their contents are stored once per distinct file under .amail/blobs/.
+
+Examples:
+  amail attachment save abc123 build.log
+  amail attachment save abc123 build.log -o /tmp/build.log
+  amail attachment save abc123 build.log -o - | tail
+  amail attachment gc`,
+}
+
+var attachmentSaveCmd = &cobra.Command{
+	Use:   "save <message-id> <name>",
+	Short: "Save an attachment to a file",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAttachmentSave,
+}
+
+var attachmentGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove blobs no message references",
+	Args:  cobra.NoArgs,
+	RunE:  runAttachmentGC,
+}
+
+var (
+	attachmentOutput string
+	attachmentForce  bool
+)
+
+func init() {
+	attachmentSaveCmd.Flags().StringVarP(&attachmentOutput, "output", "o", "", "Output path, or - for stdout (default: attachment name in current directory)")
+	attachmentSaveCmd.Flags().BoolVarP(&attachmentForce, "force", "f", false, "Overwrite an existing file")
+	attachmentCmd.AddCommand(attachmentSaveCmd)
+	attachmentCmd.AddCommand(attachmentGCCmd)
+	rootCmd.AddCommand(attachmentCmd)
+}
+
+// findAttachmentMessage finds a message by ID prefix among the copies role
+// holds, recalled ones excepted, or the messages role sent
+func findAttachmentMessage(database *db.DB, prefix, role string) (*db.InboxMessage, error) {
+	msg, err := findMessageWithStatus(database, prefix, role, db.StatusUnread, db.StatusRead, db.StatusArchived, db.StatusTrashed)
+	if err != nil {
+		return nil, err
+	}
+	if msg != nil {
+		return msg, nil
+	}
+	if msg, err := findAnyMessage(database, prefix); err == nil && msg.FromID == role {
+		return msg, nil
+	}
+	return nil, fmt.Errorf("message not found: %s", prefix)
+}
+
+func runAttachmentSave(cmd *cobra.Command, args []string) error {
+	idArg := args[0]
+	name := args[1]
+
+	// Open project and resolve identity
+	database, toID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	msg, err := findAttachmentMessage(database, idArg, toID)
+	if err != nil {
+		return err
+	}
+
+	var attachment *db.Attachment
+	for i := range msg.Attachments {
+		if msg.Attachments[i].Name == name {
+			attachment = &msg.Attachments[i]
+		}
+	}
+	if attachment == nil {
+		return fmt.Errorf("message %s has no attachment named %q", SafeShortID(msg.ID), name)
+	}
+
+	blob, err := database.OpenBlob(attachment.Hash)
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	// Write to stdout; no JSON envelope since the contents are the output
+	if attachmentOutput == "-" {
+		_, err := io.Copy(os.Stdout, blob)
+		return err
+	}
+
+	path := attachmentOutput
+	if path == "" {
+		path = filepath.Base(attachment.Name)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if attachmentForce {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := io.Copy(f, blob); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(AttachmentSaveOutput{
+			MessageID: msg.ID,
+			Name:      attachment.Name,
+			Path:      path,
+			Size:      attachment.Size,
+		})
+	}
+
+	// Text output
+	fmt.Printf("✓ Saved %s (%s) to %s\n", attachment.Name, formatSize(attachment.Size), path)
+	return nil
+}
+
+func runAttachmentGC(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, _, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	removed, err := database.CollectGarbage()
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(AttachmentGCOutput{Removed: removed})
+	}
+
+	// Text output
+	fmt.Printf("✓ Removed %d unreferenced blob(s)\n", removed)
+	return nil
+}
+
+// storeAttachments copies the files at paths into the blob store and
+// returns attachments named after each file
+func storeAttachments(database *db.DB, paths []string) ([]db.Attachment, error) {
+	var attachments []db.Attachment
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate attachment name: %s", name)
+		}
+		seen[name] = true
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open attachment: %w", err)
+		}
+		if info, err := f.Stat(); err == nil && info.IsDir() {
+			f.Close()
+			return nil, fmt.Errorf("cannot attach a directory: %s", path)
+		}
+
+		hash, size, err := database.StoreBlob(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, db.Attachment{Name: name, Hash: hash, Size: size})
+	}
+
+	return attachments, nil
+}
+
+// toAttachmentsJSON converts attachments to their JSON representation
+func toAttachmentsJSON(attachments []db.Attachment) []AttachmentJSON {
+	if len(attachments) == 0 {
+		return nil
+	}
+	result := make([]AttachmentJSON, len(attachments))
+	for i, a := range attachments {
+		result[i] = AttachmentJSON{Name: a.Name, Size: a.Size, Hash: a.Hash}
+	}
+	return result
+}
+
+// printAttachments lists attachments under a message in text output
+func printAttachments(attachments []db.Attachment) {
+	if len(attachments) == 0 {
+		return
+	}
+	fmt.Println("Attachments:")
+	for _, a := range attachments {
+		fmt.Printf("  %s (%s)\n", a.Name, formatSize(a.Size))
+	}
+	fmt.Println()
+}
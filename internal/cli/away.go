@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// AwayOutput is the JSON output structure for the away and back commands
+type AwayOutput struct {
+	Role string    `json:"role"`
+	Away *AwayJSON `json:"away"`
+}
+
+// AwayJSON is the JSON representation of a role's away state
+type AwayJSON struct {
+	ForwardTo string  `json:"forward_to,omitempty"`
+	Message   string  `json:"message,omitempty"`
+	Until     *string `json:"until,omitempty"`
+	Since     string  `json:"since"`
+}
+
+var awayCmd = &cobra.Command{
+	Use:   "away",
+	Short: "Mark your role as away",
+	Long: `Mark your role as away. Until you run 'amail back' or the --until time
+passes, every message to your role is still delivered to you, and also:
+
+  - forwarded to the --forward-to role, in the same thread
+  - answered with an auto-reply, once per sender (except notifications)
+
+Scheduled messages are handled once they are delivered, if your role is
+away at that time.
+
+Examples:
+  amail away --forward-to dev --until 18:00 --message "At the dentist"
+  amail away --until 2d
+  amail back`,
+	Args: cobra.NoArgs,
+	RunE: runAway,
+}
+
+var backCmd = &cobra.Command{
+	Use:   "back",
+	Short: "End your away state",
+	Long: `End the away state set with 'amail away'. Mail is no longer forwarded
+or auto-answered.
+
+Examples:
+  amail back`,
+	Args: cobra.NoArgs,
+	RunE: runBack,
+}
+
+var (
+	awayForwardTo string
+	awayUntil     string
+	awayMessage   string
+)
+
+func init() {
+	awayCmd.Flags().StringVar(&awayForwardTo, "forward-to", "", "Forward incoming mail to this role")
+	awayCmd.Flags().StringVar(&awayUntil, "until", "", "When you are back: time (18:00), delay (2h) or date/time")
+	awayCmd.Flags().StringVarP(&awayMessage, "message", "m", "", "Auto-reply text for senders")
+	rootCmd.AddCommand(awayCmd)
+	rootCmd.AddCommand(backCmd)
+}
+
+// openAway opens the project and resolves the caller's role
+func openAway() (*db.DB, *config.Config, string, error) {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		database.Close()
+		return nil, nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity; away applies to the whole role
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		database.Close()
+		return nil, nil, "", err
+	}
+	if err := useSession(database, res); err != nil {
+		database.Close()
+		return nil, nil, "", err
+	}
+
+	return database, cfg, res.Identity, nil
+}
+
+func runAway(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	var until *time.Time
+	if awayUntil != "" {
+		t, err := parseUntil(awayUntil, now)
+		if err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+		until = &t
+	}
+
+	database, cfg, role, err := openAway()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if awayForwardTo != "" {
+		if !cfg.IsValidRole(awayForwardTo) {
+			return fmt.Errorf("unknown role: %s (valid roles: %v)", awayForwardTo, cfg.AllRoles())
+		}
+		if awayForwardTo == role {
+			return fmt.Errorf("cannot forward to yourself")
+		}
+	}
+
+	a := db.Away{
+		Role:      role,
+		ForwardTo: awayForwardTo,
+		Message:   awayMessage,
+		Until:     until,
+		StartedAt: now,
+	}
+	if err := database.SetAway(a); err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(AwayOutput{Role: role, Away: toAwayJSON(&a)})
+	}
+
+	// Text output
+	fmt.Printf("✓ %s is %s\n", role, formatAway(&a))
+	fmt.Println("  End with: amail back")
+	return nil
+}
+
+func runBack(cmd *cobra.Command, args []string) error {
+	database, _, role, err := openAway()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	ok, err := database.ClearAway(role)
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(AwayOutput{Role: role})
+	}
+
+	// Text output
+	if !ok {
+		fmt.Printf("%s was not away.\n", role)
+		return nil
+	}
+	fmt.Printf("✓ Welcome back, %s\n", role)
+	return nil
+}
+
+func toAwayJSON(a *db.Away) *AwayJSON {
+	if a == nil {
+		return nil
+	}
+	return &AwayJSON{
+		ForwardTo: a.ForwardTo,
+		Message:   a.Message,
+		Until:     formatOptionalTime(a.Until),
+		Since:     a.StartedAt.Format(time.RFC3339),
+	}
+}
+
+// formatAway describes an away state, e.g. "away until 18:00, forwarding
+// to dev"
+func formatAway(a *db.Away) string {
+	s := "away"
+	if a.Until != nil {
+		s += " until " + a.Until.Local().Format("2006-01-02 15:04")
+	}
+	if a.ForwardTo != "" {
+		s += ", forwarding to " + a.ForwardTo
+	}
+	return s
+}
@@ -27,6 +27,7 @@ type CheckMessageJSON struct {
 	Subject   string   `json:"subject"`
 	Priority  string   `json:"priority"`
 	CreatedAt string   `json:"created_at"`
+	Starred   bool     `json:"starred,omitempty"`
 }
 
 var checkCmd = &cobra.Command{
@@ -62,18 +63,27 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Only commands that send mail load the rules, so report bad ones here
+	if _, err := loadRules(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid rules: %v (see 'amail rules')\n", err)
+	}
+
 	// Resolve identity
 	res, err := identity.MustResolve(cfg)
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	toID := res.Identity
 
 	// Get unread messages
-	messages, err := database.GetInbox(toID, false)
+	messages, err := database.GetInbox(toID, db.StatusUnread)
 	if err != nil {
 		return fmt.Errorf("failed to get inbox: %w", err)
 	}
+	db.SortStarredFirst(messages)
 
 	// Execute notifications if requested (do this before output so it happens regardless of format)
 	if checkNotify {
@@ -101,16 +111,8 @@ func runCheck(cmd *cobra.Command, args []string) error {
 			Messages: make([]CheckMessageJSON, len(messages)),
 			Count:    len(messages),
 		}
-		for i, m := range messages {
-			output.Messages[i] = CheckMessageJSON{
-				ID:        m.ID,
-				ShortID:   SafeShortID(m.ID),
-				From:      m.FromID,
-				To:        m.ToIDs,
-				Subject:   m.Subject,
-				Priority:  m.Priority,
-				CreatedAt: m.CreatedAt.Format(time.RFC3339),
-			}
+		for i := range messages {
+			output.Messages[i] = toCheckMessageJSON(&messages[i])
 		}
 		return PrintJSON(output)
 	}
@@ -132,8 +134,12 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Println()
 		for _, msg := range messages {
-			fmt.Printf("  [%s] %s: %s (%s)\n",
-				SafeShortID(msg.ID), msg.FromID, msg.Subject, formatTimeAgo(msg.CreatedAt))
+			star := ""
+			if msg.StarredAt != nil {
+				star = "★ "
+			}
+			fmt.Printf("  %s[%s] %s: %s (%s)\n",
+				star, SafeShortID(msg.ID), msg.FromID, msg.Subject, formatTimeAgo(msg.CreatedAt))
 		}
 		fmt.Println()
 		fmt.Println("Use --notify to trigger notifications")
@@ -141,3 +147,17 @@ func runCheck(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// toCheckMessageJSON converts a message to its check/watch JSON representation
+func toCheckMessageJSON(m *db.InboxMessage) CheckMessageJSON {
+	return CheckMessageJSON{
+		ID:        m.ID,
+		ShortID:   SafeShortID(m.ID),
+		From:      m.FromID,
+		To:        m.ToIDs,
+		Subject:   m.Subject,
+		Priority:  m.Priority,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		Starred:   m.StarredAt != nil,
+	}
+}
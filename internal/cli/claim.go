@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// ClaimOutput is the JSON output structure for the claim command
+type ClaimOutput struct {
+	Claimed    bool        `json:"claimed"`
+	Message    *ReadOutput `json:"message,omitempty"`
+	LeaseUntil *string     `json:"lease_until,omitempty"`
+}
+
+// ClaimActionOutput is the JSON output structure for claim --done and release
+type ClaimActionOutput struct {
+	ID      string `json:"id"`
+	ShortID string `json:"short_id"`
+	Action  string `json:"action"`
+}
+
+var claimCmd = &cobra.Command{
+	Use:   "claim",
+	Short: "Take the next message from a pool role's queue",
+	Long: `Take the oldest unclaimed message sent to your role, for roles listed
+as pools in .amail/config.toml:
+
+  [agents]
+  pools = ["dev"]
+
+A claimed message is held by your session until you finish it with
+--done or release it (hand it back). If you do neither before the lease runs out,
+it is redelivered to the next session that claims.
+
+Examples:
+  amail claim
+  amail claim --lease 30m
+  amail claim --list
+  amail claim --done abc123
+  amail release abc123`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runClaim,
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release <message-id>",
+	Short: "Hand a claimed message back to the queue",
+	Long: `Give up a message you claimed with 'amail claim' so another session can
+claim it right away.
+
+Examples:
+  amail release abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRelease,
+}
+
+var (
+	claimLease string
+	claimList  bool
+	claimDone  bool
+)
+
+func init() {
+	claimCmd.Flags().StringVar(&claimLease, "lease", "", "How long to hold the message (e.g. 30m; default from config)")
+	claimCmd.Flags().BoolVar(&claimList, "list", false, "List the messages you hold instead of claiming")
+	claimCmd.Flags().BoolVar(&claimDone, "done", false, "Finish a claimed message: it is marked read and never redelivered")
+	rootCmd.AddCommand(claimCmd)
+	rootCmd.AddCommand(releaseCmd)
+}
+
+// openPool opens the project and resolves the caller's identity, which
+// must be a pool role. Returns the role and the session claiming for it:
+// the role instance, or the role itself.
+func openPool() (*db.DB, *config.Config, string, string, error) {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return nil, nil, "", "", err
+	}
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		database.Close()
+		return nil, nil, "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		database.Close()
+		return nil, nil, "", "", err
+	}
+
+	if !cfg.IsPool(res.Identity) {
+		database.Close()
+		return nil, nil, "", "", fmt.Errorf("%s is not a pool role (add it to pools under [agents])", res.Identity)
+	}
+	if err := useSession(database, res); err != nil {
+		database.Close()
+		return nil, nil, "", "", err
+	}
+
+	return database, cfg, res.Identity, res.Name(), nil
+}
+
+func runClaim(cmd *cobra.Command, args []string) error {
+	if claimDone {
+		if len(args) != 1 {
+			return fmt.Errorf("--done requires a message ID")
+		}
+		return finishClaim(args[0], true)
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %s (use --done to finish a claim)", args[0])
+	}
+
+	database, cfg, role, claimer, err := openPool()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if claimList {
+		return listClaims(database, role, claimer)
+	}
+
+	lease := time.Duration(cfg.Queue.Lease) * time.Second
+	if claimLease != "" {
+		lease, err = parseDuration(claimLease)
+		if err != nil {
+			return err
+		}
+	}
+	if lease <= 0 {
+		return fmt.Errorf("lease must be positive")
+	}
+
+	now := time.Now()
+	msg, err := database.ClaimNext(role, claimer, lease, now)
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		if msg == nil {
+			return PrintJSON(ClaimOutput{})
+		}
+		output := toReadOutput(msg)
+		leaseUntil := now.Add(lease)
+		return PrintJSON(ClaimOutput{
+			Claimed:    true,
+			Message:    &output,
+			LeaseUntil: formatOptionalTime(&leaseUntil),
+		})
+	}
+
+	// Text output
+	if msg == nil {
+		fmt.Println("No messages to claim.")
+		return nil
+	}
+	displayMessage(msg)
+	fmt.Printf("Claimed until %s. Finish with: amail claim --done %s\n",
+		now.Add(lease).Format("15:04:05"), SafeShortID(msg.ID))
+	return nil
+}
+
+// listClaims prints the messages claimer holds and has not finished
+func listClaims(database *db.DB, role, claimer string) error {
+	claims, err := database.GetClaims(role, claimer)
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := InboxOutput{
+			Messages: make([]InboxMessageJSON, len(claims)),
+			Count:    len(claims),
+		}
+		for i := range claims {
+			output.Messages[i] = toInboxMessageJSON(&claims[i])
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(claims) == 0 {
+		fmt.Println("No claimed messages.")
+		return nil
+	}
+	printMessageTable(claims, func(m *db.InboxMessage) string {
+		return strings.Join(m.ToIDs, ",")
+	})
+	return nil
+}
+
+func runRelease(cmd *cobra.Command, args []string) error {
+	return finishClaim(args[0], false)
+}
+
+// finishClaim marks one of the caller's claims done, or releases it back
+// to the queue
+func finishClaim(prefix string, done bool) error {
+	database, _, role, claimer, err := openPool()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	claims, err := database.GetClaims(role, claimer)
+	if err != nil {
+		return err
+	}
+	var matches []*db.InboxMessage
+	for i := range claims {
+		if strings.HasPrefix(claims[i].ID, prefix) {
+			matches = append(matches, &claims[i])
+		}
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no claim of yours matches: %s", prefix)
+	}
+	if len(matches) > 1 {
+		return fmt.Errorf("ambiguous ID prefix: %s matches %d claims", prefix, len(matches))
+	}
+	msg := matches[0]
+
+	var ok bool
+	action := "release"
+	if done {
+		action = "done"
+		ok, err = database.AckClaim(msg.ID, role, claimer)
+	} else {
+		ok, err = database.ReleaseClaim(msg.ID, role, claimer)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("message %s is no longer claimed by you", SafeShortID(msg.ID))
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(ClaimActionOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), Action: action})
+	}
+
+	// Text output
+	if done {
+		fmt.Printf("✓ Done with %s\n", SafeShortID(msg.ID))
+	} else {
+		fmt.Printf("✓ Released %s back to the %s queue\n", SafeShortID(msg.ID), role)
+	}
+	return nil
+}
@@ -60,6 +60,9 @@ func runCount(cmd *cobra.Command, args []string) error {
 	if err != nil || res == nil {
 		return outputCount(0)
 	}
+	if err := useSession(database, res); err != nil {
+		return outputCount(0)
+	}
 
 	// Get count
 	count, err := database.CountUnread(res.Identity)
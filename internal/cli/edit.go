@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// EditOutput is the JSON output structure for the edit command
+type EditOutput struct {
+	ID       string `json:"id"`
+	ShortID  string `json:"short_id"`
+	Revision int    `json:"revision"`
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit <message-id> <body>",
+	Short: "Replace the body of a message you sent",
+	Long: `Replace the body of a message you sent. The earlier body is kept as a
+revision, and recipients see that the message was edited when they read it.
+
+Only the sender can edit a message, and recalled messages cannot be edited.
+
+Examples:
+  amail edit abc123 "GET /users endpoint at routes/users.ts:52"
+  amail read abc123 --history`,
+	Args: cobra.ExactArgs(2),
+	RunE: runEdit,
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	body := args[1]
+
+	database, fromID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	msg, err := findOwnMessage(database, args[0], fromID)
+	if err != nil {
+		return err
+	}
+	if msg.RecalledAt != nil {
+		return fmt.Errorf("message %s has been recalled", SafeShortID(msg.ID))
+	}
+	if msg.Body == body {
+		return fmt.Errorf("new body is the same as the current one")
+	}
+
+	ok, err := database.EditMessage(msg.ID, fromID, body)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("message %s has been recalled", SafeShortID(msg.ID))
+	}
+
+	revisions, err := database.GetRevisions(msg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get revisions: %w", err)
+	}
+	revision := len(revisions) + 1
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(EditOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), Revision: revision})
+	}
+
+	// Text output
+	fmt.Printf("✓ Edited %s (revision %d)\n", SafeShortID(msg.ID), revision)
+	return nil
+}
+
+// findOwnMessage finds a message by ID prefix and checks that fromID sent it
+func findOwnMessage(database *db.DB, prefix, fromID string) (*db.InboxMessage, error) {
+	msg, err := findAnyMessage(database, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if msg.FromID != fromID {
+		return nil, fmt.Errorf("message %s was sent by %s, not %s", SafeShortID(msg.ID), msg.FromID, fromID)
+	}
+	return msg, nil
+}
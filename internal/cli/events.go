@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// EventJSON is the JSON representation of a mailbox event, one per line
+type EventJSON struct {
+	Seq       int64  `json:"seq"`
+	Type      string `json:"type"`
+	MessageID string `json:"message_id"`
+	ShortID   string `json:"short_id"`
+	Actor     string `json:"actor"`
+	From      string `json:"from,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show the mailbox event log",
+	Long: `Show the append-only log of mailbox events for every role: sent, read,
+archived, unarchived, trashed, undeleted, purged, snoozed, notified,
+edited and recalled, plus request progress: acknowledged, in-progress,
+done, declined, open (when reopened) and escalated (when overdue), and
+pool queue activity: claimed, released and acked.
+
+Use --type to show only some types (repeatable).
+
+Each event has a sequence number. Pass the last one you saw to --since to
+resume after a restart. With --follow, keeps running and prints new
+events as they happen.
+
+In JSON mode, events are printed as NDJSON (one object per line, no
+envelope) so the stream can be consumed incrementally.
+
+Examples:
+  amail events
+  amail events --follow --json
+  amail events --since 1042 --follow
+  amail events --type claimed --type released`,
+	Args: cobra.NoArgs,
+	RunE: runEvents,
+}
+
+var (
+	eventsSince  int64
+	eventsFollow bool
+	eventsTypes  []string
+)
+
+// eventsBatchSize limits how many events are loaded at once
+const eventsBatchSize = 500
+
+func init() {
+	eventsCmd.Flags().Int64Var(&eventsSince, "since", 0, "Only events after this sequence number")
+	eventsCmd.Flags().BoolVarP(&eventsFollow, "follow", "f", false, "Keep printing new events as they happen")
+	eventsCmd.Flags().StringArrayVar(&eventsTypes, "type", nil, "Only events of this type (repeatable): "+strings.Join(db.EventTypes, ", "))
+	rootCmd.AddCommand(eventsCmd)
+}
+
+func runEvents(cmd *cobra.Command, args []string) error {
+	valid := make(map[string]bool)
+	for _, t := range db.EventTypes {
+		valid[t] = true
+	}
+	types := make(map[string]bool)
+	for _, t := range eventsTypes {
+		if !valid[t] {
+			return fmt.Errorf("invalid event type: %s (must be one of %s)", t, strings.Join(db.EventTypes, ", "))
+		}
+		types[t] = true
+	}
+
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	jsonOutput := IsJSONOutput()
+	cursor := eventsSince
+
+	// Print everything after the cursor, in batches
+	printNew := func() error {
+		for {
+			events, err := database.GetEvents(cursor, eventsBatchSize)
+			if err != nil {
+				return err
+			}
+			for i := range events {
+				cursor = events[i].Seq
+				if len(types) > 0 && !types[events[i].Type] {
+					continue
+				}
+				if jsonOutput {
+					if err := PrintNDJSON(toEventJSON(&events[i])); err != nil {
+						return err
+					}
+				} else {
+					printEvent(&events[i])
+				}
+			}
+			if len(events) < eventsBatchSize {
+				return nil
+			}
+		}
+	}
+
+	if !eventsFollow {
+		return printNew()
+	}
+
+	// Set up signal handling
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Subscribe before the first read so nothing in between is missed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := database.Subscribe(ctx, pollInterval(cfg, 0))
+	if err != nil {
+		return err
+	}
+
+	if err := printNew(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-changes:
+			if err := printNew(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading events: %v\n", err)
+			}
+		case <-sigChan:
+			return nil
+		}
+	}
+}
+
+// toEventJSON converts an event to its JSON representation
+func toEventJSON(e *db.Event) EventJSON {
+	return EventJSON{
+		Seq:       e.Seq,
+		Type:      e.Type,
+		MessageID: e.MessageID,
+		ShortID:   SafeShortID(e.MessageID),
+		Actor:     e.Actor,
+		From:      e.FromID,
+		Subject:   e.Subject,
+		CreatedAt: e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// printEvent prints an event as a human-readable line
+func printEvent(e *db.Event) {
+	fmt.Printf("%6d  %s  %-10s  [%s] %s  %s\n",
+		e.Seq, e.CreatedAt.Local().Format("2006-01-02 15:04:05"), e.Type,
+		SafeShortID(e.MessageID), e.Actor, e.Subject)
+}
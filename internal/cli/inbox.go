@@ -29,6 +29,33 @@ type InboxMessageJSON struct {
 	Priority  string   `json:"priority"`
 	Status    string   `json:"status"`
 	CreatedAt string   `json:"created_at"`
+	Starred   bool     `json:"starred,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Edited    bool     `json:"edited,omitempty"`
+	Recalled  bool     `json:"recalled,omitempty"`
+	DueAt     *string  `json:"due_at,omitempty"`
+	Overdue   bool     `json:"overdue,omitempty"`
+
+	// Recipients carries per-recipient delivery state for sent messages
+	Recipients []RecipientStatusJSON `json:"recipients,omitempty"`
+}
+
+// RecipientStatusJSON is the JSON representation of one recipient's delivery state
+type RecipientStatusJSON struct {
+	To         string  `json:"to"`
+	Status     string  `json:"status"`
+	ReadAt     *string `json:"read_at,omitempty"`
+	NotifiedAt *string `json:"notified_at,omitempty"`
+	ArchivedAt *string `json:"archived_at,omitempty"`
+	TrashedAt  *string `json:"trashed_at,omitempty"`
+
+	// RequestState is the recipient's progress on a request
+	RequestState string `json:"request_state,omitempty"`
+
+	// ClaimedBy is the session holding a pool role's copy
+	ClaimedBy  string  `json:"claimed_by,omitempty"`
+	LeaseUntil *string `json:"lease_until,omitempty"`
+	AckedAt    *string `json:"acked_at,omitempty"`
 }
 
 var inboxCmd = &cobra.Command{
@@ -36,23 +63,34 @@ var inboxCmd = &cobra.Command{
 	Short: "List messages in inbox",
 	Long: `List messages in your inbox.
 
-By default shows only unread messages.
+By default shows only unread messages. Starred messages are listed
+first. Filtering by --label or --starred shows read messages too.
+Archived messages are only listed with --archived.
 
 Examples:
   amail inbox
-  amail inbox -a         # Show all messages
-  amail inbox --from dev # Filter by sender`,
+  amail inbox -a              # Show all messages
+  amail inbox --from dev      # Filter by sender
+  amail inbox --label blocked # Filter by label
+  amail inbox --starred       # Only starred messages
+  amail inbox --archived      # Show the archive`,
 	RunE: runInbox,
 }
 
 var (
-	inboxAll  bool
-	inboxFrom string
+	inboxAll      bool
+	inboxFrom     string
+	inboxLabel    string
+	inboxStarred  bool
+	inboxArchived bool
 )
 
 func init() {
 	inboxCmd.Flags().BoolVarP(&inboxAll, "all", "a", false, "Show all messages (including read)")
 	inboxCmd.Flags().StringVar(&inboxFrom, "from", "", "Filter by sender")
+	inboxCmd.Flags().StringVar(&inboxLabel, "label", "", "Filter by label (includes read messages)")
+	inboxCmd.Flags().BoolVar(&inboxStarred, "starred", false, "Show only starred messages (includes read messages)")
+	inboxCmd.Flags().BoolVar(&inboxArchived, "archived", false, "Show archived messages instead of the inbox")
 	rootCmd.AddCommand(inboxCmd)
 }
 
@@ -75,10 +113,28 @@ func runInbox(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	toID := res.Identity
 
+	label := ""
+	if inboxLabel != "" {
+		if label, err = normalizeLabel(inboxLabel); err != nil {
+			return err
+		}
+	}
+
+	// Pick the folder
+	statuses := []string{db.StatusUnread}
+	if inboxArchived {
+		statuses = []string{db.StatusArchived}
+	} else if inboxAll || label != "" || inboxStarred {
+		statuses = append(statuses, db.StatusRead)
+	}
+
 	// Get messages
-	messages, err := database.GetInbox(toID, inboxAll)
+	messages, err := database.GetInbox(toID, statuses...)
 	if err != nil {
 		return fmt.Errorf("failed to get inbox: %w", err)
 	}
@@ -94,30 +150,51 @@ func runInbox(cmd *cobra.Command, args []string) error {
 		messages = filtered
 	}
 
+	// Filter by label if specified
+	if label != "" {
+		var filtered []db.InboxMessage
+		for _, m := range messages {
+			if m.HasLabel(label) {
+				filtered = append(filtered, m)
+			}
+		}
+		messages = filtered
+	}
+
+	// Filter by star if specified
+	if inboxStarred {
+		var filtered []db.InboxMessage
+		for _, m := range messages {
+			if m.StarredAt != nil {
+				filtered = append(filtered, m)
+			}
+		}
+		messages = filtered
+	}
+
+	db.SortStarredFirst(messages)
+
 	// JSON output
 	if IsJSONOutput() {
 		output := InboxOutput{
 			Messages: make([]InboxMessageJSON, len(messages)),
 			Count:    len(messages),
 		}
-		for i, m := range messages {
-			output.Messages[i] = InboxMessageJSON{
-				ID:        m.ID,
-				ShortID:   SafeShortID(m.ID),
-				From:      m.FromID,
-				To:        m.ToIDs,
-				Subject:   m.Subject,
-				Priority:  m.Priority,
-				Status:    m.Status,
-				CreatedAt: m.CreatedAt.Format(time.RFC3339),
-			}
+		for i := range messages {
+			output.Messages[i] = toInboxMessageJSON(&messages[i])
 		}
 		return PrintJSON(output)
 	}
 
 	// Text output
 	if len(messages) == 0 {
-		if inboxAll {
+		if inboxArchived {
+			fmt.Println("No archived messages.")
+		} else if inboxStarred {
+			fmt.Println("No starred messages.")
+		} else if label != "" {
+			fmt.Printf("No messages labelled %s.\n", label)
+		} else if inboxAll {
 			fmt.Println("No messages.")
 		} else {
 			fmt.Println("No unread messages.")
@@ -125,14 +202,87 @@ func runInbox(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Print table
+	printMessageTable(messages, func(m *db.InboxMessage) string {
+		return strings.Join(m.ToIDs, ",")
+	})
+
+	return nil
+}
+
+// toInboxMessageJSON converts a message to its inbox JSON representation
+func toInboxMessageJSON(m *db.InboxMessage) InboxMessageJSON {
+	return InboxMessageJSON{
+		ID:        m.ID,
+		ShortID:   SafeShortID(m.ID),
+		From:      m.FromID,
+		To:        m.ToIDs,
+		Subject:   m.Subject,
+		Priority:  m.Priority,
+		Status:    m.Status,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		Starred:   m.StarredAt != nil,
+		Labels:    m.Labels,
+		Edited:    m.EditedAt != nil,
+		Recalled:  m.RecalledAt != nil,
+		DueAt:     formatOptionalTime(m.DueAt),
+		Overdue:   m.Overdue(time.Now()),
+	}
+}
+
+// toRecipientStatusJSON converts recipient rows to their JSON representation
+func toRecipientStatusJSON(recipients []db.Recipient) []RecipientStatusJSON {
+	result := make([]RecipientStatusJSON, len(recipients))
+	for i, r := range recipients {
+		result[i] = RecipientStatusJSON{To: r.ToID, Status: r.Status, RequestState: r.RequestState, ClaimedBy: r.ClaimedBy}
+		if r.ReadAt != nil {
+			at := r.ReadAt.Format(time.RFC3339)
+			result[i].ReadAt = &at
+		}
+		if r.NotifiedAt != nil {
+			at := r.NotifiedAt.Format(time.RFC3339)
+			result[i].NotifiedAt = &at
+		}
+		if r.ArchivedAt != nil {
+			at := r.ArchivedAt.Format(time.RFC3339)
+			result[i].ArchivedAt = &at
+		}
+		if r.TrashedAt != nil {
+			at := r.TrashedAt.Format(time.RFC3339)
+			result[i].TrashedAt = &at
+		}
+		result[i].LeaseUntil = formatOptionalTime(r.LeaseUntil)
+		result[i].AckedAt = formatOptionalTime(r.AckedAt)
+	}
+	return result
+}
+
+// printMessageTable prints the inbox-style message table. toColumn renders
+// the TO column for each message. A DUE column is added when any message
+// has a deadline.
+func printMessageTable(messages []db.InboxMessage, toColumn func(m *db.InboxMessage) string) {
+	showDue := false
+	for i := range messages {
+		if messages[i].DueAt != nil {
+			showDue = true
+			break
+		}
+	}
+	now := time.Now()
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tFROM\tSUBJECT\tTO\tPRIORITY\tTIME")
-	fmt.Fprintln(w, "--\t----\t-------\t--\t--------\t----")
+	if showDue {
+		fmt.Fprintln(w, "ID\tFROM\tSUBJECT\tTO\tPRIORITY\tDUE\tTIME")
+		fmt.Fprintln(w, "--\t----\t-------\t--\t--------\t---\t----")
+	} else {
+		fmt.Fprintln(w, "ID\tFROM\tSUBJECT\tTO\tPRIORITY\tTIME")
+		fmt.Fprintln(w, "--\t----\t-------\t--\t--------\t----")
+	}
+
+	for i := range messages {
+		m := &messages[i]
 
-	for _, m := range messages {
 		// Format recipients
-		toStr := strings.Join(m.ToIDs, ",")
+		toStr := toColumn(m)
 		if len([]rune(toStr)) > 20 {
 			toStr = string([]rune(toStr)[:17]) + "..."
 		}
@@ -142,12 +292,18 @@ func runInbox(cmd *cobra.Command, args []string) error {
 		if subject == "" {
 			subject = "(no subject)"
 		}
-		subject = truncate(subject, 30)
+		subject = truncate(subject, 30) + revisionTag(&m.Message)
+		if len(m.Labels) > 0 {
+			subject += " " + formatLabels(m.Labels)
+		}
 
 		// Add status indicator
 		statusIndicator := ""
+		if m.StarredAt != nil {
+			statusIndicator = "★"
+		}
 		if m.Status == "unread" {
-			statusIndicator = "*"
+			statusIndicator += "*"
 		}
 
 		// Priority indicator
@@ -158,11 +314,48 @@ func runInbox(cmd *cobra.Command, args []string) error {
 			priorityStr = "! high"
 		}
 
+		if showDue {
+			priorityStr += "\t" + formatDue(m, now)
+		}
+
 		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n",
 			statusIndicator, SafeShortID(m.ID), m.FromID, subject, toStr, priorityStr, formatTimeAgo(m.CreatedAt))
 	}
 
 	w.Flush()
+}
 
-	return nil
+// revisionTag marks a message the sender has recalled or edited
+func revisionTag(m *db.Message) string {
+	switch {
+	case m.RecalledAt != nil:
+		return " (recalled)"
+	case m.EditedAt != nil:
+		return " (edited)"
+	}
+	return ""
+}
+
+// formatDue renders a request's deadline relative to now, e.g. "in 3 hours"
+// or "⚠ overdue 20 min". Deadlines that passed after the request was
+// finished are shown as a date.
+func formatDue(m *db.InboxMessage, now time.Time) string {
+	switch {
+	case m.DueAt == nil:
+		return ""
+	case m.Overdue(now):
+		return "⚠ overdue " + formatSpan(now.Sub(*m.DueAt))
+	case m.DueAt.After(now):
+		return "in " + formatSpan(m.DueAt.Sub(now))
+	}
+	return m.DueAt.Local().Format("Jan 2 15:04")
+}
+
+// formatSpan is formatDuration without "just now", for spans with a
+// direction
+func formatSpan(d time.Duration) string {
+	if d < time.Minute {
+		return "<1 min"
+	}
+	return formatDuration(d)
 }
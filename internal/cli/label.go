@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// LabelOutput is the JSON output structure for the label command
+type LabelOutput struct {
+	ID      string   `json:"id"`
+	ShortID string   `json:"short_id"`
+	Labels  []string `json:"labels"`
+}
+
+// labelPattern is what a label may look like once lowercased
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_./:-]*$`)
+
+var labelCmd = &cobra.Command{
+	Use:   "label <message-id> [+label|-label]...",
+	Short: "Add or remove labels on a message",
+	Long: `Add or remove your own labels on a message. Labels are private to
+each recipient and are shown by inbox, read and the TUI.
+
+Prefix a label with + to add it (the + is optional) or - to remove it.
+With no changes, prints the message's labels.
+
+Examples:
+  amail label abc123 +blocked -todo
+  amail label abc123 review
+  amail label abc123
+  amail inbox --label blocked`,
+	// Flag parsing would treat -todo as shorthand flags
+	DisableFlagParsing: true,
+	RunE:               runLabel,
+}
+
+func init() {
+	rootCmd.AddCommand(labelCmd)
+}
+
+func runLabel(cmd *cobra.Command, args []string) error {
+	// Handle the flags cobra would have parsed
+	var positional []string
+loop:
+	for i, arg := range args {
+		switch arg {
+		case "-h", "--help":
+			return cmd.Help()
+		case "--json":
+			forceJSON = true
+		case "--text":
+			forceText = true
+		case "--":
+			positional = append(positional, args[i+1:]...)
+			break loop
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("message ID required")
+	}
+
+	add, remove, err := parseLabelChanges(positional[1:])
+	if err != nil {
+		return err
+	}
+
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
+	toID := res.Identity
+
+	msg, err := findMessageByPrefix(database, positional[0], toID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message not found: %s", positional[0])
+	}
+
+	if err := database.UpdateLabels(msg.ID, toID, add, remove); err != nil {
+		return err
+	}
+
+	labels, err := database.GetLabels(msg.ID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to get labels: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		if labels == nil {
+			labels = []string{}
+		}
+		return PrintJSON(LabelOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), Labels: labels})
+	}
+
+	// Text output
+	if len(labels) == 0 {
+		fmt.Printf("%s has no labels\n", SafeShortID(msg.ID))
+		return nil
+	}
+	if len(add) > 0 || len(remove) > 0 {
+		fmt.Print("✓ ")
+	}
+	fmt.Printf("%s: %s\n", SafeShortID(msg.ID), formatLabels(labels))
+	return nil
+}
+
+// parseLabelChanges splits +label/-label arguments into labels to add and
+// remove. Labels are lowercased; a bare label is added.
+func parseLabelChanges(args []string) (add, remove []string, err error) {
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		removing := strings.HasPrefix(arg, "-")
+		name, err := normalizeLabel(strings.TrimLeft(arg, "+-"))
+		if err != nil {
+			return nil, nil, err
+		}
+		if seen[name] {
+			return nil, nil, fmt.Errorf("label %s given more than once", name)
+		}
+		seen[name] = true
+
+		if removing {
+			remove = append(remove, name)
+		} else {
+			add = append(add, name)
+		}
+	}
+	return add, remove, nil
+}
+
+// normalizeLabel lowercases a label and checks that it is well formed
+func normalizeLabel(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !labelPattern.MatchString(name) {
+		return "", fmt.Errorf("invalid label: %q (use letters, digits and - _ . / :)", name)
+	}
+	return name, nil
+}
+
+// formatLabels renders labels as chips, e.g. "#blocked #todo"
+func formatLabels(labels []string) string {
+	chips := make([]string, len(labels))
+	for i, l := range labels {
+		chips[i] = "#" + l
+	}
+	return strings.Join(chips, " ")
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabelChanges(t *testing.T) {
+	add, remove, err := parseLabelChanges([]string{"+Blocked", "-todo", "review"})
+	if err != nil {
+		t.Fatalf("parseLabelChanges failed: %v", err)
+	}
+	if !reflect.DeepEqual(add, []string{"blocked", "review"}) {
+		t.Errorf("add = %v, want [blocked review]", add)
+	}
+	if !reflect.DeepEqual(remove, []string{"todo"}) {
+		t.Errorf("remove = %v, want [todo]", remove)
+	}
+
+	for _, args := range [][]string{
+		{"+"},
+		{"+has space"},
+		{"+a,b"},
+		{"+todo", "-todo"},
+	} {
+		if _, _, err := parseLabelChanges(args); err == nil {
+			t.Errorf("parseLabelChanges(%q) expected error", args)
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	if got := formatLabels([]string{"blocked", "todo"}); got != "#blocked #todo" {
+		t.Errorf("formatLabels = %q", got)
+	}
+	if got := formatLabelCounts(map[string]int{"todo": 1, "blocked": 2, "a": 1}); got != "#blocked:2 #a:1 #todo:1" {
+		t.Errorf("formatLabelCounts = %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thirteen37/amail/internal/config"
@@ -13,6 +14,7 @@ type ListOutput struct {
 	Roles         []string          `json:"roles"`
 	Groups        map[string]GroupJSON `json:"groups,omitempty"`
 	BuiltinGroups []string          `json:"builtin_groups"`
+	Away          map[string]*AwayJSON `json:"away,omitempty"`
 }
 
 // GroupJSON is the JSON representation of a group
@@ -35,11 +37,12 @@ func init() {
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	// Find project root
-	root, err := db.FindProjectRoot()
+	// Open project
+	database, root, err := db.OpenProject()
 	if err != nil {
 		return err
 	}
+	defer database.Close()
 
 	// Load config
 	cfg, err := config.LoadProject(root)
@@ -50,12 +53,23 @@ func runList(cmd *cobra.Command, args []string) error {
 	// Build roles list (including reserved "user")
 	roles := append(cfg.Agents.Roles, "user")
 
+	away, err := database.GetAllAway(time.Now())
+	if err != nil {
+		return err
+	}
+
 	// JSON output
 	if IsJSONOutput() {
 		output := ListOutput{
 			Roles:         roles,
 			BuiltinGroups: []string{"@all", "@agents", "@others"},
 		}
+		if len(away) > 0 {
+			output.Away = make(map[string]*AwayJSON)
+			for role, a := range away {
+				output.Away[role] = toAwayJSON(a)
+			}
+		}
 		if len(cfg.Groups) > 0 {
 			output.Groups = make(map[string]GroupJSON)
 			for name, members := range cfg.Groups {
@@ -68,9 +82,17 @@ func runList(cmd *cobra.Command, args []string) error {
 	// Text output
 	fmt.Println("Roles:")
 	for _, role := range cfg.Agents.Roles {
-		fmt.Printf("  %s\n", role)
+		if a := away[role]; a != nil {
+			fmt.Printf("  %s (%s)\n", role, formatAway(a))
+		} else {
+			fmt.Printf("  %s\n", role)
+		}
+	}
+	if a := away["user"]; a != nil {
+		fmt.Printf("  user (reserved, %s)\n", formatAway(a))
+	} else {
+		fmt.Println("  user (reserved)")
 	}
-	fmt.Println("  user (reserved)")
 
 	if len(cfg.Groups) > 0 {
 		fmt.Println()
@@ -34,24 +34,66 @@ Examples:
 	RunE: runArchive,
 }
 
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <message-id>",
+	Short: "Move an archived message back to the inbox",
+	Long: `Move an archived message back to your inbox, marked as read.
+
+Examples:
+  amail inbox --archived
+  amail unarchive abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUnarchive,
+}
+
 var deleteCmd = &cobra.Command{
 	Use:   "delete <message-id>",
-	Short: "Delete a message from your inbox",
-	Long: `Delete a message from your inbox.
+	Short: "Move a message to the trash",
+	Long: `Move a message from your inbox to the trash.
 
 This only removes the message from your view; other recipients still have it.
+Trashed messages can be restored with 'amail undelete' until the trash is
+emptied with 'amail trash empty'.
 
 Examples:
-  amail delete abc123`,
+  amail delete abc123
+  amail undelete abc123`,
 	Args: cobra.ExactArgs(1),
 	RunE: runDelete,
 }
 
+var starCmd = &cobra.Command{
+	Use:   "star <message-id>",
+	Short: "Star a message",
+	Long: `Star a message to pin it. Starred messages are listed first by inbox
+and check, and shown with --starred even after they are read.
+
+Examples:
+  amail star abc123
+  amail inbox --starred`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStar,
+}
+
+var unstarCmd = &cobra.Command{
+	Use:   "unstar <message-id>",
+	Short: "Remove the star from a message",
+	Long: `Remove the star from a message.
+
+Examples:
+  amail unstar abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStar,
+}
+
 func init() {
 	markReadCmd.Flags().BoolVar(&markReadAll, "all", false, "Mark all unread messages as read")
 	rootCmd.AddCommand(markReadCmd)
 	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(starCmd)
+	rootCmd.AddCommand(unstarCmd)
 }
 
 func runMarkRead(cmd *cobra.Command, args []string) error {
@@ -73,6 +115,9 @@ func runMarkRead(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	toID := res.Identity
 
 	if markReadAll {
@@ -149,6 +194,50 @@ func runArchive(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runUnarchive(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	toID := res.Identity
+
+	messageID := args[0]
+
+	// Find message by prefix
+	msg, err := findMessageByPrefix(database, messageID, toID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message not found: %s", messageID)
+	}
+
+	ok, err := database.Unarchive(msg.ID, toID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("message %s is not archived", SafeShortID(msg.ID))
+	}
+
+	fmt.Printf("✓ Unarchived %s\n", SafeShortID(msg.ID))
+	return nil
+}
+
 func runDelete(cmd *cobra.Command, args []string) error {
 	// Open project
 	database, root, err := db.OpenProject()
@@ -181,10 +270,58 @@ func runDelete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("message not found: %s", messageID)
 	}
 
-	if err := database.Delete(msg.ID, toID); err != nil {
+	if err := database.Trash(msg.ID, toID); err != nil {
 		return fmt.Errorf("failed to delete: %w", err)
 	}
 
-	fmt.Printf("✓ Deleted %s\n", SafeShortID(msg.ID))
+	fmt.Printf("✓ Moved %s to trash (undo with: amail undelete %s)\n", SafeShortID(msg.ID), SafeShortID(msg.ID))
+	return nil
+}
+
+// runStar handles both star and unstar
+func runStar(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	toID := res.Identity
+
+	messageID := args[0]
+
+	// Find message by prefix
+	msg, err := findMessageByPrefix(database, messageID, toID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message not found: %s", messageID)
+	}
+
+	if cmd.Name() == "unstar" {
+		if err := database.Unstar(msg.ID, toID); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Unstarred %s\n", SafeShortID(msg.ID))
+		return nil
+	}
+
+	if err := database.Star(msg.ID, toID); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Starred %s\n", SafeShortID(msg.ID))
 	return nil
 }
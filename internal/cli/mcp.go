@@ -0,0 +1,432 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run as an MCP server over stdio",
+	Long: `Run a Model Context Protocol server over stdin/stdout, so MCP-capable
+agents can use the mailbox as native tools instead of shelling out.
+
+Tools: send, inbox, read, reply, thread, wait, whoami. Their input schemas
+are generated from the flags and arguments of the matching commands.
+
+Identity is resolved once at startup, like any other command
+($AMAIL_IDENTITY or tmux session mapping).
+
+Example MCP client configuration:
+  {"mcpServers": {"amail": {"command": "amail", "args": ["mcp"],
+                            "env": {"AMAIL_IDENTITY": "dev"}}}}`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+// mcpToolCommands are the commands exposed as MCP tools
+var mcpToolCommands = []*cobra.Command{sendCmd, inboxCmd, readCmd, replyCmd, threadCmd, waitCmd, whoamiCmd}
+
+// mcpProtocolVersions are the MCP protocol versions this server speaks, newest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	// Find project root
+	root, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity once so every tool call acts as the same role
+	env := os.Environ()
+	res, err := identity.Resolve(cfg)
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		env = append(env, identity.EnvIdentity+"="+res.Name())
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate amail executable: %w", err)
+	}
+
+	server := &mcpServer{
+		tools: mcpToolCommands,
+		run: func(args []string) ([]byte, error) {
+			c := exec.Command(exe, args...)
+			c.Env = env
+			c.Dir = root
+			return c.Output()
+		},
+	}
+	return server.serve(os.Stdin, os.Stdout)
+}
+
+// JSON-RPC error codes used by the MCP server
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool describes a tool in a tools/list response
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// mcpContent is a text block in a tools/call result
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpToolResult is the result of a tools/call request
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// mcpServer serves MCP requests, running each tool as an amail subcommand
+type mcpServer struct {
+	tools []*cobra.Command
+	// run executes amail with the given arguments and returns its stdout
+	run func(args []string) ([]byte, error)
+
+	mu  sync.Mutex // Serializes writes to out
+	out *json.Encoder
+}
+
+// serve reads newline-delimited JSON-RPC requests from r until EOF. Tool
+// calls run concurrently so a blocking wait doesn't stall other requests.
+func (s *mcpServer) serve(r io.Reader, w io.Writer) error {
+	s.out = json.NewEncoder(w)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.reply(rpcResponse{ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}})
+			continue
+		}
+
+		if req.Method == "tools/call" {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handle(req)
+			}()
+			continue
+		}
+		s.handle(req)
+	}
+	return scanner.Err()
+}
+
+// handle answers a single request; notifications get no response
+func (s *mcpServer) handle(req rpcRequest) {
+	result, rpcErr := s.dispatch(req)
+	if len(req.ID) == 0 {
+		return
+	}
+	s.reply(rpcResponse{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+func (s *mcpServer) dispatch(req rpcRequest) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+
+		version := mcpProtocolVersions[0]
+		for _, v := range mcpProtocolVersions {
+			if v == params.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "amail", "version": Version},
+		}, nil
+
+	case "ping", "notifications/initialized":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		tools := make([]mcpTool, len(s.tools))
+		for i, c := range s.tools {
+			tools[i] = toolFromCommand(c)
+		}
+		return map[string]interface{}{"tools": tools}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{rpcInvalidParams, err.Error()}
+		}
+
+		c := s.findTool(params.Name)
+		if c == nil {
+			return nil, &rpcError{rpcInvalidParams, "unknown tool: " + params.Name}
+		}
+
+		args, err := toolArgs(c, params.Arguments)
+		if err != nil {
+			return mcpToolResult{Content: []mcpContent{{"text", err.Error()}}, IsError: true}, nil
+		}
+		return s.callTool(args), nil
+	}
+
+	return nil, &rpcError{rpcMethodNotFound, "method not found: " + req.Method}
+}
+
+// callTool runs amail with --json and returns its envelope as the tool result
+func (s *mcpServer) callTool(args []string) mcpToolResult {
+	// --json goes right after the command name, ahead of any "--"
+	out, err := s.run(append([]string{args[0], "--json"}, args[1:]...))
+
+	var envelope Response
+	if jsonErr := json.Unmarshal(out, &envelope); jsonErr != nil {
+		msg := strings.TrimSpace(string(out))
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		} else if err != nil && msg == "" {
+			msg = err.Error()
+		}
+		return mcpToolResult{Content: []mcpContent{{"text", msg}}, IsError: err != nil}
+	}
+
+	if !envelope.Success {
+		msg := "command failed"
+		if envelope.Error != nil {
+			msg = envelope.Error.Message
+		}
+		return mcpToolResult{Content: []mcpContent{{"text", msg}}, IsError: true}
+	}
+
+	data, _ := json.MarshalIndent(envelope.Data, "", "  ")
+	return mcpToolResult{Content: []mcpContent{{"text", string(data)}}}
+}
+
+func (s *mcpServer) findTool(name string) *cobra.Command {
+	for _, c := range s.tools {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *mcpServer) reply(resp rpcResponse) {
+	resp.JSONRPC = "2.0"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Encode(resp)
+}
+
+// useArgPattern matches <required> and [optional] arguments in a command's Use
+var useArgPattern = regexp.MustCompile(`[<\[]([a-z-]+)[>\]]`)
+
+// commandArg is a positional argument parsed from a command's Use line
+type commandArg struct {
+	Name     string // Property name in the tool schema
+	Required bool
+}
+
+// commandArgs returns the positional arguments declared in a command's Use
+func commandArgs(c *cobra.Command) []commandArg {
+	var args []commandArg
+	for _, m := range useArgPattern.FindAllStringSubmatch(c.Use, -1) {
+		args = append(args, commandArg{
+			Name:     schemaName(m[1]),
+			Required: strings.HasPrefix(m[0], "<"),
+		})
+	}
+	return args
+}
+
+// toolFlags returns the command's own flags that make sense as tool inputs
+func toolFlags(c *cobra.Command) []*pflag.Flag {
+	var flags []*pflag.Flag
+	c.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name != "help" && !f.Hidden {
+			flags = append(flags, f)
+		}
+	})
+	return flags
+}
+
+// toolFromCommand builds an MCP tool definition from a command's positional
+// arguments and flags
+func toolFromCommand(c *cobra.Command) mcpTool {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for _, a := range commandArgs(c) {
+		properties[a.Name] = map[string]interface{}{"type": "string"}
+		if a.Required {
+			required = append(required, a.Name)
+		}
+	}
+
+	for _, f := range toolFlags(c) {
+		prop := map[string]interface{}{
+			"type":        schemaType(f.Value.Type()),
+			"description": f.Usage,
+		}
+		if prop["type"] == "array" {
+			prop["items"] = map[string]string{"type": "string"}
+		} else if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			prop["default"] = f.DefValue
+		}
+		properties[schemaName(f.Name)] = prop
+	}
+
+	// Use the long help without its shell examples, which don't apply to tools
+	description := c.Short
+	if c.Long != "" {
+		description, _, _ = strings.Cut(c.Long, "\nExamples:")
+		description = strings.TrimSpace(description)
+	}
+
+	return mcpTool{
+		Name:        c.Name(),
+		Description: description,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		},
+	}
+}
+
+// toolArgs converts tool call arguments to a command line for c. Flags come
+// first and positional arguments last, after "--", so a value starting with
+// a dash is never parsed as a flag.
+func toolArgs(c *cobra.Command, arguments map[string]interface{}) ([]string, error) {
+	args := []string{c.Name()}
+
+	for _, f := range toolFlags(c) {
+		v, ok := arguments[schemaName(f.Name)]
+		if !ok {
+			continue
+		}
+		// Repeatable flags take an array, passed as one flag per element
+		values, isArray := v.([]interface{})
+		if !isArray {
+			values = []interface{}{v}
+		}
+		for _, value := range values {
+			args = append(args, "--"+f.Name+"="+formatToolArg(value))
+		}
+	}
+
+	var positional []string
+	for _, a := range commandArgs(c) {
+		v, ok := arguments[a.Name]
+		if !ok {
+			if a.Required {
+				return nil, fmt.Errorf("missing required argument: %s", a.Name)
+			}
+			continue
+		}
+		positional = append(positional, formatToolArg(v))
+	}
+	if len(positional) > 0 {
+		args = append(append(args, "--"), positional...)
+	}
+
+	return args, nil
+}
+
+// formatToolArg formats a JSON argument value as a command-line string
+func formatToolArg(v interface{}) string {
+	// JSON numbers decode as float64; keep whole numbers out of exponent form
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return fmt.Sprint(v)
+}
+
+// schemaName converts a flag or argument name to a schema property name
+func schemaName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// schemaType maps a pflag value type to a JSON schema type
+func schemaType(flagType string) string {
+	switch flagType {
+	case "bool":
+		return "boolean"
+	case "int", "int64":
+		return "integer"
+	case "stringArray", "stringSlice":
+		return "array"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToolFromCommand(t *testing.T) {
+	tool := toolFromCommand(replyCmd)
+
+	if tool.Name != "reply" {
+		t.Errorf("Name = %q, want %q", tool.Name, "reply")
+	}
+
+	required := tool.InputSchema["required"].([]string)
+	if !reflect.DeepEqual(required, []string{"message_id", "body"}) {
+		t.Errorf("required = %v, want [message_id body]", required)
+	}
+
+	props := tool.InputSchema["properties"].(map[string]interface{})
+	tests := map[string]string{
+		"message_id": "string",
+		"body":       "string",
+		"all":        "boolean",
+		"priority":   "string",
+		"type":       "string",
+	}
+	for name, want := range tests {
+		prop, ok := props[name].(map[string]interface{})
+		if !ok {
+			t.Errorf("missing property %q", name)
+			continue
+		}
+		if prop["type"] != want {
+			t.Errorf("%s type = %v, want %s", name, prop["type"], want)
+		}
+	}
+	if _, ok := props["json"]; ok {
+		t.Error("global --json flag should not be a tool input")
+	}
+
+	// Optional positional arguments aren't required
+	read := toolFromCommand(readCmd)
+	if len(read.InputSchema["required"].([]string)) != 0 {
+		t.Errorf("read should have no required arguments, got %v", read.InputSchema["required"])
+	}
+}
+
+func TestToolArgs(t *testing.T) {
+	args, err := toolArgs(replyCmd, map[string]interface{}{
+		"message_id": "abc123",
+		"body":       "done",
+		"all":        true,
+	})
+	if err != nil {
+		t.Fatalf("toolArgs failed: %v", err)
+	}
+	want := []string{"reply", "--all=true", "--", "abc123", "done"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	if _, err := toolArgs(replyCmd, map[string]interface{}{"body": "done"}); err == nil {
+		t.Error("expected error for missing message_id")
+	}
+
+	args, err = toolArgs(sendCmd, map[string]interface{}{
+		"to":      "qa",
+		"subject": "logs",
+		"body":    "attached",
+		"attach":  []interface{}{"a.log", "b.log"},
+	})
+	if err != nil {
+		t.Fatalf("toolArgs failed: %v", err)
+	}
+	want = []string{"send", "--attach=a.log", "--attach=b.log", "--", "qa", "logs", "attached"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	// A body that looks like a flag stays a positional argument
+	args, err = toolArgs(replyCmd, map[string]interface{}{"message_id": "abc123", "body": "--all"})
+	if err != nil {
+		t.Fatalf("toolArgs failed: %v", err)
+	}
+	want = []string{"reply", "--", "abc123", "--all"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+	if err := replyCmd.ParseFlags(args[1:]); err != nil || replyCmd.Flags().Args()[1] != "--all" {
+		t.Errorf("expected --all to parse as the body, got %v, %v", replyCmd.Flags().Args(), err)
+	}
+
+	if got := formatToolArg(float64(1000000)); got != "1000000" {
+		t.Errorf("formatToolArg(1e6) = %q, want %q", got, "1000000")
+	}
+}
+
+func TestMCPServer(t *testing.T) {
+	var calls [][]string
+	server := &mcpServer{
+		tools: mcpToolCommands,
+		run: func(args []string) ([]byte, error) {
+			calls = append(calls, args)
+			return []byte(`{"success": true, "data": {"identity": "dev"}}`), nil
+		},
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"whoami","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"bogus"}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := server.serve(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+
+	responses := map[string]map[string]json.RawMessage{}
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response line %q: %v", scanner.Text(), err)
+		}
+		responses[string(resp["id"])] = resp
+	}
+	if len(responses) != 4 {
+		t.Fatalf("got %d responses, want 4 (notification gets none):\n%s", len(responses), out.String())
+	}
+
+	var initResult struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	json.Unmarshal(responses["1"]["result"], &initResult)
+	if initResult.ProtocolVersion != "2024-11-05" {
+		t.Errorf("protocolVersion = %q, want %q", initResult.ProtocolVersion, "2024-11-05")
+	}
+
+	var listResult struct {
+		Tools []mcpTool `json:"tools"`
+	}
+	json.Unmarshal(responses["2"]["result"], &listResult)
+	if len(listResult.Tools) != len(mcpToolCommands) {
+		t.Errorf("got %d tools, want %d", len(listResult.Tools), len(mcpToolCommands))
+	}
+
+	var callResult mcpToolResult
+	json.Unmarshal(responses["3"]["result"], &callResult)
+	if callResult.IsError || len(callResult.Content) != 1 || !strings.Contains(callResult.Content[0].Text, `"dev"`) {
+		t.Errorf("unexpected tool result: %+v", callResult)
+	}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], []string{"whoami", "--json"}) {
+		t.Errorf("run called with %v, want [[whoami --json]]", calls)
+	}
+
+	if _, ok := responses["4"]["error"]; !ok {
+		t.Error("expected error for unknown method")
+	}
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// MigrateStatusOutput is the JSON output structure for the migrate status command
+type MigrateStatusOutput struct {
+	CurrentVersion int             `json:"current_version"`
+	LatestVersion  int             `json:"latest_version"`
+	Pending        int             `json:"pending"`
+	Migrations     []MigrationJSON `json:"migrations"`
+}
+
+// MigrationJSON is the JSON representation of a schema migration
+type MigrationJSON struct {
+	Version   int     `json:"version"`
+	Name      string  `json:"name"`
+	Applied   bool    `json:"applied"`
+	AppliedAt *string `json:"applied_at,omitempty"`
+}
+
+// MigrateUpOutput is the JSON output structure for the migrate up command
+type MigrateUpOutput struct {
+	Applied int `json:"applied"`
+	Version int `json:"version"`
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the mailbox database schema",
+	Long: `Inspect and apply schema migrations for the project mailbox.
+
+Migrations are applied automatically whenever amail opens the database,
+so this command is mostly useful to check what an upgrade will change.
+
+Examples:
+  amail migrate status
+  amail migrate up`,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateStatus,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateUp,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// openProjectNoMigrate opens the project database without upgrading it
+func openProjectNoMigrate() (*db.DB, error) {
+	root, err := db.FindProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	return db.OpenNoMigrate(db.DBPath(root))
+}
+
+func runMigrateStatus(cmd *cobra.Command, args []string) error {
+	database, err := openProjectNoMigrate()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	current, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := database.Migrations()
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, s := range statuses {
+		if !s.Applied {
+			pending++
+		}
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := MigrateStatusOutput{
+			CurrentVersion: current,
+			LatestVersion:  db.LatestSchemaVersion(),
+			Pending:        pending,
+			Migrations:     make([]MigrationJSON, len(statuses)),
+		}
+		for i, s := range statuses {
+			output.Migrations[i] = MigrationJSON{
+				Version: s.Version,
+				Name:    s.Name,
+				Applied: s.Applied,
+			}
+			if s.AppliedAt != nil {
+				at := s.AppliedAt.Format(time.RFC3339)
+				output.Migrations[i].AppliedAt = &at
+			}
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	fmt.Printf("Schema version: %d (latest: %d)\n", current, db.LatestSchemaVersion())
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	fmt.Fprintln(w, "-------\t----\t------")
+	for _, s := range statuses {
+		status := "pending"
+		if s.AppliedAt != nil {
+			status = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, status)
+	}
+	w.Flush()
+
+	if pending > 0 {
+		fmt.Println()
+		fmt.Printf("%d pending migration(s). Run 'amail migrate up' to apply.\n", pending)
+	}
+
+	return nil
+}
+
+func runMigrateUp(cmd *cobra.Command, args []string) error {
+	database, err := openProjectNoMigrate()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	applied, err := database.Migrate()
+	if err != nil {
+		return err
+	}
+
+	version, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(MigrateUpOutput{Applied: applied, Version: version})
+	}
+
+	// Text output
+	if applied == 0 {
+		fmt.Printf("✓ Schema is up to date (version %d)\n", version)
+		return nil
+	}
+	fmt.Printf("✓ Applied %d migration(s), now at version %d\n", applied, version)
+	return nil
+}
@@ -49,6 +49,12 @@ func PrintJSON(data interface{}) error {
 	return enc.Encode(resp)
 }
 
+// PrintNDJSON outputs data as a single compact JSON line, without the
+// envelope, for streaming commands
+func PrintNDJSON(data interface{}) error {
+	return json.NewEncoder(os.Stdout).Encode(data)
+}
+
 // PrintJSONError outputs an error in the standard JSON envelope format
 func PrintJSONError(err error, code string) error {
 	resp := Response{
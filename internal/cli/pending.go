@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+var pendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "List requests you sent that are still open",
+	Long: `List requests you sent that at least one recipient has not finished or
+declined yet, oldest first, with each recipient's progress.
+
+A request is done for a recipient once they reply with type response or
+run 'amail tasks done'.
+
+Examples:
+  amail pending
+  amail pending --json`,
+	Args: cobra.NoArgs,
+	RunE: runPending,
+}
+
+func init() {
+	rootCmd.AddCommand(pendingCmd)
+}
+
+func runPending(cmd *cobra.Command, args []string) error {
+	database, fromID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	pending, err := database.GetPending(fromID)
+	if err != nil {
+		return fmt.Errorf("failed to get pending requests: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := InboxOutput{
+			Messages: make([]InboxMessageJSON, len(pending)),
+			Count:    len(pending),
+		}
+		for i := range pending {
+			output.Messages[i] = toInboxMessageJSON(&pending[i].InboxMessage)
+			output.Messages[i].Recipients = toRecipientStatusJSON(pending[i].Recipients)
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(pending) == 0 {
+		fmt.Println("No pending requests.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSUBJECT\tWAITING ON\tPRIORITY\tAGE")
+	fmt.Fprintln(w, "--\t-------\t----------\t--------\t---")
+	for _, m := range pending {
+		subject := m.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			SafeShortID(m.ID), truncate(subject, 30), formatRequestStates(m.Recipients),
+			m.Priority, formatTimeAgo(m.CreatedAt))
+	}
+	w.Flush()
+
+	return nil
+}
+
+// formatRequestStates lists the recipients still working on a request with
+// their state, e.g. "dev (in-progress), qa (open)"
+func formatRequestStates(recipients []db.Recipient) string {
+	var parts []string
+	for _, r := range recipients {
+		switch r.RequestState {
+		case db.RequestOpen, db.RequestAcknowledged, db.RequestInProgress:
+			if r.Status != db.StatusRecalled {
+				parts = append(parts, fmt.Sprintf("%s (%s)", r.ToID, r.RequestState))
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/thirteen37/amail/internal/db"
+)
+
+func TestFormatRequestStates(t *testing.T) {
+	recipients := []db.Recipient{
+		{ToID: "dev", Status: "read", RequestState: db.RequestInProgress},
+		{ToID: "qa", Status: "unread", RequestState: db.RequestOpen},
+		{ToID: "pm", Status: "read", RequestState: db.RequestDone},
+		{ToID: "ops", Status: "recalled", RequestState: db.RequestOpen},
+	}
+
+	got := formatRequestStates(recipients)
+	want := "dev (in-progress), qa (open)"
+	if got != want {
+		t.Errorf("formatRequestStates() = %q, want %q", got, want)
+	}
+}
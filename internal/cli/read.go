@@ -13,18 +13,31 @@ import (
 
 // ReadOutput is the JSON output structure for the read command
 type ReadOutput struct {
-	ID        string   `json:"id"`
-	ShortID   string   `json:"short_id"`
-	From      string   `json:"from"`
-	To        []string `json:"to"`
-	Subject   string   `json:"subject"`
-	Body      string   `json:"body"`
-	Priority  string   `json:"priority"`
-	Type      string   `json:"type"`
-	Status    string   `json:"status"`
-	ThreadID  *string  `json:"thread_id,omitempty"`
-	ReplyToID *string  `json:"reply_to_id,omitempty"`
-	CreatedAt string   `json:"created_at"`
+	ID          string           `json:"id"`
+	ShortID     string           `json:"short_id"`
+	From        string           `json:"from"`
+	To          []string         `json:"to"`
+	Subject     string           `json:"subject"`
+	Body        string           `json:"body"`
+	Priority    string           `json:"priority"`
+	Type        string           `json:"type"`
+	Status      string           `json:"status"`
+	ThreadID    *string          `json:"thread_id,omitempty"`
+	ReplyToID   *string          `json:"reply_to_id,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+	Attachments []AttachmentJSON `json:"attachments,omitempty"`
+	Labels      []string         `json:"labels,omitempty"`
+	Starred     bool             `json:"starred,omitempty"`
+	EditedAt    *string          `json:"edited_at,omitempty"`
+	RecalledAt  *string          `json:"recalled_at,omitempty"`
+	Revisions   []RevisionJSON   `json:"revisions,omitempty"`
+}
+
+// RevisionJSON is the JSON representation of an earlier body of a message
+type RevisionJSON struct {
+	Revision  int    `json:"revision"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
 }
 
 var readCmd = &cobra.Command{
@@ -32,19 +45,27 @@ var readCmd = &cobra.Command{
 	Short: "Read a message",
 	Long: `Read a message and mark it as read.
 
-If --latest is specified, reads the most recent unread message.
+If --latest is specified, reads the unread message at the top of the inbox
+(starred messages first, then the most recent).
+Messages the sender has edited or recalled say so; --history also shows
+the earlier bodies of an edited message.
 
 Examples:
   amail read abc123
-  amail read --latest`,
+  amail read --latest
+  amail read abc123 --history`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runRead,
 }
 
-var readLatest bool
+var (
+	readLatest  bool
+	readHistory bool
+)
 
 func init() {
-	readCmd.Flags().BoolVar(&readLatest, "latest", false, "Read the most recent unread message")
+	readCmd.Flags().BoolVar(&readLatest, "latest", false, "Read the unread message at the top of the inbox")
+	readCmd.Flags().BoolVar(&readHistory, "history", false, "Show earlier revisions of an edited message")
 	rootCmd.AddCommand(readCmd)
 }
 
@@ -67,6 +88,9 @@ func runRead(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	toID := res.Identity
 
 	var msg *db.InboxMessage
@@ -110,35 +134,77 @@ func runRead(cmd *cobra.Command, args []string) error {
 		msg.Status = "read" // Update local copy for accurate output
 	}
 
+	var revisions []db.Revision
+	if readHistory && msg.EditedAt != nil {
+		revisions, err = database.GetRevisions(msg.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get revisions: %w", err)
+		}
+	}
+
 	// JSON output
 	if IsJSONOutput() {
-		output := ReadOutput{
-			ID:        msg.ID,
-			ShortID:   SafeShortID(msg.ID),
-			From:      msg.FromID,
-			To:        msg.ToIDs,
-			Subject:   msg.Subject,
-			Body:      msg.Body,
-			Priority:  msg.Priority,
-			Type:      msg.MsgType,
-			Status:    msg.Status,
-			ThreadID:  msg.ThreadID,
-			ReplyToID: msg.ReplyToID,
-			CreatedAt: msg.CreatedAt.Format(time.RFC3339),
+		output := toReadOutput(msg)
+		for _, r := range revisions {
+			output.Revisions = append(output.Revisions, RevisionJSON{
+				Revision:  r.Number,
+				Body:      r.Body,
+				CreatedAt: r.CreatedAt.Format(time.RFC3339),
+			})
 		}
 		return PrintJSON(output)
 	}
 
 	// Text output
 	displayMessage(msg)
+	printRevisions(revisions)
 
 	return nil
 }
 
+// toReadOutput converts a message to the JSON output of the read command
+func toReadOutput(msg *db.InboxMessage) ReadOutput {
+	return ReadOutput{
+		ID:          msg.ID,
+		ShortID:     SafeShortID(msg.ID),
+		From:        msg.FromID,
+		To:          msg.ToIDs,
+		Subject:     msg.Subject,
+		Body:        msg.Body,
+		Priority:    msg.Priority,
+		Type:        msg.MsgType,
+		Status:      msg.Status,
+		ThreadID:    msg.ThreadID,
+		ReplyToID:   msg.ReplyToID,
+		CreatedAt:   msg.CreatedAt.Format(time.RFC3339),
+		Attachments: toAttachmentsJSON(msg.Attachments),
+		Labels:      msg.Labels,
+		Starred:     msg.StarredAt != nil,
+		EditedAt:    formatOptionalTime(msg.EditedAt),
+		RecalledAt:  formatOptionalTime(msg.RecalledAt),
+	}
+}
+
+// formatOptionalTime formats t as RFC3339, or returns nil if t is nil
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
 // findMessageByPrefix finds a message by ID prefix in the recipient's inbox
+// or archive
 func findMessageByPrefix(database *db.DB, prefix, toID string) (*db.InboxMessage, error) {
+	return findMessageWithStatus(database, prefix, toID, db.StatusUnread, db.StatusRead, db.StatusArchived)
+}
+
+// findMessageWithStatus finds a message by ID prefix among the recipient's
+// messages with one of statuses
+func findMessageWithStatus(database *db.DB, prefix, toID string, statuses ...string) (*db.InboxMessage, error) {
 	// Get all messages for recipient
-	messages, err := database.GetInbox(toID, true)
+	messages, err := database.GetInbox(toID, statuses...)
 	if err != nil {
 		return nil, err
 	}
@@ -175,9 +241,31 @@ func displayMessage(msg *db.InboxMessage) {
 	if msg.ThreadID != nil {
 		fmt.Printf("Thread:   %s\n", *msg.ThreadID)
 	}
+	if len(msg.Labels) > 0 {
+		fmt.Printf("Labels:   %s\n", formatLabels(msg.Labels))
+	}
+	if msg.EditedAt != nil {
+		fmt.Printf("Edited:   %s (%s)\n", msg.EditedAt.Format("2006-01-02 15:04:05"), formatTimeAgo(*msg.EditedAt))
+	}
+	if msg.RecalledAt != nil {
+		fmt.Printf("Recalled: %s (the sender withdrew this message)\n", msg.RecalledAt.Format("2006-01-02 15:04:05"))
+	}
 
 	fmt.Println(strings.Repeat("-", 60))
 	fmt.Println()
 	fmt.Println(msg.Body)
 	fmt.Println()
+	printAttachments(msg.Attachments)
+}
+
+// printRevisions prints the earlier bodies of an edited message, newest first
+func printRevisions(revisions []db.Revision) {
+	for i := len(revisions) - 1; i >= 0; i-- {
+		r := revisions[i]
+		fmt.Println(strings.Repeat("-", 60))
+		fmt.Printf("Revision %d (%s)\n", r.Number, r.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Println()
+		fmt.Println(r.Body)
+		fmt.Println()
+	}
 }
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// RecallOutput is the JSON output structure for the recall command
+type RecallOutput struct {
+	ID        string   `json:"id"`
+	ShortID   string   `json:"short_id"`
+	Withdrawn []string `json:"withdrawn"`
+	Marked    []string `json:"marked"`
+}
+
+var recallCmd = &cobra.Command{
+	Use:   "recall <message-id>",
+	Short: "Withdraw a message you sent",
+	Long: `Withdraw a message you sent. Recipients who have not read it yet no
+longer see it; those who already read it keep it, marked as recalled.
+
+Only the sender can recall a message. To fix a mistake instead, use
+'amail edit'.
+
+Examples:
+  amail recall abc123
+  amail status abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRecall,
+}
+
+func init() {
+	rootCmd.AddCommand(recallCmd)
+}
+
+func runRecall(cmd *cobra.Command, args []string) error {
+	database, fromID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	msg, err := findOwnMessage(database, args[0], fromID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := database.RecallMessage(msg.ID, fromID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("message %s has already been recalled", SafeShortID(msg.ID))
+	}
+
+	recipients, err := database.GetRecipientStatus(msg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient status: %w", err)
+	}
+	output := RecallOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), Withdrawn: []string{}, Marked: []string{}}
+	for _, r := range recipients {
+		if r.Status == db.StatusRecalled {
+			output.Withdrawn = append(output.Withdrawn, r.ToID)
+		} else {
+			output.Marked = append(output.Marked, r.ToID)
+		}
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(output)
+	}
+
+	// Text output
+	fmt.Printf("✓ Recalled %s\n", SafeShortID(msg.ID))
+	if len(output.Withdrawn) > 0 {
+		fmt.Printf("  Withdrawn from: %s\n", strings.Join(output.Withdrawn, ", "))
+	}
+	if len(output.Marked) > 0 {
+		fmt.Printf("  Already read by: %s (marked recalled)\n", strings.Join(output.Marked, ", "))
+	}
+	return nil
+}
@@ -71,33 +71,71 @@ func runReply(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := useRules(database, cfg); err != nil {
+		return err
+	}
 
 	// Resolve sender identity
 	res, err := identity.MustResolve(cfg)
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	fromID := res.Identity
 
-	// Find the original message
-	originalMsg, err := findMessageByPrefix(database, messageIDArg, fromID)
+	// Build the reply
+	msg, recipients, err := newReply(database, fromID, messageIDArg, replyAll, body, replyPriority, replyType)
 	if err != nil {
 		return err
 	}
+	threadID := *msg.ThreadID
+
+	// Send
+	if err := database.SendMessage(msg, recipients); err != nil {
+		return fmt.Errorf("failed to send reply: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := ReplyOutput{
+			ID:         msg.ID,
+			ShortID:    SafeShortID(msg.ID),
+			ThreadID:   threadID,
+			Recipients: recipients,
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	fmt.Printf("✓ Sent %s to: %s (thread: %s)\n", SafeShortID(msg.ID), strings.Join(recipients, ", "), SafeShortID(threadID))
+
+	return nil
+}
+
+// newReply builds a reply from fromID to the message identified by idArg.
+// With all set, it goes to the sender and every original recipient.
+func newReply(database *db.DB, fromID, idArg string, all bool, body, priority, msgType string) (*db.Message, []string, error) {
+	// Find the original message
+	originalMsg, err := findMessageByPrefix(database, idArg, fromID)
+	if err != nil {
+		return nil, nil, err
+	}
 	if originalMsg == nil {
 		// Try to find by global search (in case user is replying to a message they sent)
-		originalMsg, err = findMessageGlobally(database, messageIDArg)
+		originalMsg, err = findMessageGlobally(database, idArg)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		if originalMsg == nil {
-			return fmt.Errorf("message not found: %s", messageIDArg)
+			return nil, nil, fmt.Errorf("message not found: %s", idArg)
 		}
 	}
 
 	// Determine recipients
 	var recipients []string
-	if replyAll {
+	if all {
 		// Include original sender + all original recipients (minus self)
 		recipients = append(recipients, originalMsg.FromID)
 		recipients = append(recipients, originalMsg.ToIDs...)
@@ -106,13 +144,13 @@ func runReply(cmd *cobra.Command, args []string) error {
 	} else {
 		// Just reply to sender
 		if originalMsg.FromID == fromID {
-			return fmt.Errorf("cannot reply to your own message without --all")
+			return nil, nil, fmt.Errorf("cannot reply to your own message without --all")
 		}
 		recipients = []string{originalMsg.FromID}
 	}
 
 	if len(recipients) == 0 {
-		return fmt.Errorf("no recipients for reply")
+		return nil, nil, fmt.Errorf("no recipients for reply")
 	}
 
 	// Determine thread ID
@@ -133,37 +171,18 @@ func runReply(cmd *cobra.Command, args []string) error {
 
 	// Create reply message
 	msg := &db.Message{
-		ID:        generateID(),
+		ID:        db.NewID(),
 		FromID:    fromID,
 		Subject:   subject,
 		Body:      body,
-		Priority:  replyPriority,
-		MsgType:   replyType,
+		Priority:  priority,
+		MsgType:   msgType,
 		ThreadID:  &threadID,
 		ReplyToID: &originalMsg.ID,
 		CreatedAt: time.Now(),
 	}
 
-	// Send
-	if err := database.SendMessage(msg, recipients); err != nil {
-		return fmt.Errorf("failed to send reply: %w", err)
-	}
-
-	// JSON output
-	if IsJSONOutput() {
-		output := ReplyOutput{
-			ID:         msg.ID,
-			ShortID:    SafeShortID(msg.ID),
-			ThreadID:   threadID,
-			Recipients: recipients,
-		}
-		return PrintJSON(output)
-	}
-
-	// Text output
-	fmt.Printf("✓ Sent %s to: %s (thread: %s)\n", SafeShortID(msg.ID), strings.Join(recipients, ", "), SafeShortID(threadID))
-
-	return nil
+	return msg, recipients, nil
 }
 
 // findMessageGlobally finds a message by ID prefix without recipient filter
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -26,11 +27,47 @@ func Execute() error {
 
 	err := rootCmd.Execute()
 	if err != nil && IsJSONOutput() {
-		PrintJSONError(err, "")
+		code := ""
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.Reason
+		}
+		PrintJSONError(err, code)
 	}
 	return err
 }
 
+// ExitTimeout is the exit code used when a blocking command times out,
+// matching timeout(1)
+const ExitTimeout = 124
+
+// ExitError is an error that should end the process with a specific exit code
+type ExitError struct {
+	Code   int    // Process exit code
+	Reason string // Error code reported in the JSON envelope
+	Err    error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// errTimeout returns an ExitError reporting that a command timed out
+func errTimeout(format string, args ...interface{}) error {
+	return &ExitError{Code: ExitTimeout, Reason: "timeout", Err: fmt.Errorf(format, args...)}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
+
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	rootCmd.PersistentFlags().BoolVar(&forceJSON, "json", false, "Force JSON output")
@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// RulesOutput is the JSON output structure for the rules command
+type RulesOutput struct {
+	Rules []RuleJSON `json:"rules"`
+	Count int        `json:"count"`
+}
+
+// RuleJSON is the JSON representation of a configured rule
+type RuleJSON struct {
+	Name    string   `json:"name"`
+	Match   string   `json:"match"`
+	Actions []string `json:"actions"`
+}
+
+// RulesTestOutput is the JSON output structure for the rules test command
+type RulesTestOutput struct {
+	ID         string              `json:"id"`
+	ShortID    string              `json:"short_id"`
+	Recipients []RuleTestRecipient `json:"recipients"`
+}
+
+// RuleTestRecipient lists the rules that would apply to one recipient's
+// copy of a message
+type RuleTestRecipient struct {
+	Recipient string     `json:"recipient"`
+	Rules     []RuleJSON `json:"rules"`
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List the rules applied to incoming mail",
+	Long: `List the [[rules]] in .amail/config.toml. Rules run on each recipient's
+copy of a message as it is sent. Every condition given must match:
+
+  from, to           sender and recipient role
+  subject, body      regular expressions
+  priority, type     exact values
+
+Matching rules then apply their actions, in order:
+
+  label = ["fyi"]        add labels for the recipient
+  archive = true         file it in the archive
+  mark_read = true       mark it read (no notification)
+  forward_to = "qa"      forward a copy, in the same thread
+  set_priority = "low"   change the priority of the recipient's copy
+  notify = "quiet"       use [notify.quiet] commands in watch
+
+Rules also run on mail forwarded while a role is away, but not on what
+rules forward themselves or on auto-replies.
+
+For example, in .amail/config.toml:
+
+  [[rules]]
+  name = "quiet notifications"
+  type = "notification"
+  label = ["fyi"]
+  mark_read = true
+
+Examples:
+  amail rules
+  amail rules test abc123`,
+	Args: cobra.NoArgs,
+	RunE: runRules,
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test <message-id>",
+	Short: "Show which rules would apply to a message",
+	Long: `Dry-run the rules against a message already sent, showing what each
+recipient's copy would get. Nothing is changed.
+
+Examples:
+  amail rules test abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRulesTest,
+}
+
+func init() {
+	rulesCmd.AddCommand(rulesTestCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
+
+func runRules(cmd *cobra.Command, args []string) error {
+	// Find project root
+	root, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	rules, err := loadRules(cfg)
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := RulesOutput{Rules: make([]RuleJSON, len(rules)), Count: len(rules)}
+		for i := range rules {
+			output.Rules[i] = toRuleJSON(&rules[i])
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(rules) == 0 {
+		fmt.Println("No rules. Add [[rules]] blocks to", config.ConfigPath(root))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RULE\tMATCH\tACTIONS")
+	fmt.Fprintln(w, "----\t-----\t-------")
+	for i := range rules {
+		r := toRuleJSON(&rules[i])
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.Match, strings.Join(r.Actions, ", "))
+	}
+	w.Flush()
+
+	return nil
+}
+
+func runRulesTest(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	rules, err := loadRules(cfg)
+	if err != nil {
+		return err
+	}
+
+	msg, err := findMessageGlobally(database, args[0])
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message not found: %s", args[0])
+	}
+
+	output := RulesTestOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), Recipients: []RuleTestRecipient{}}
+	for _, toID := range msg.ToIDs {
+		entry := RuleTestRecipient{Recipient: toID, Rules: []RuleJSON{}}
+		for _, r := range db.MatchRules(rules, &msg.Message, toID) {
+			entry.Rules = append(entry.Rules, toRuleJSON(&r))
+		}
+		output.Recipients = append(output.Recipients, entry)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(output)
+	}
+
+	// Text output
+	fmt.Printf("Message %s from %s: %s\n\n", SafeShortID(msg.ID), msg.FromID, msg.Subject)
+	for _, rcpt := range output.Recipients {
+		if len(rcpt.Rules) == 0 {
+			fmt.Printf("%s: no rules match\n", rcpt.Recipient)
+			continue
+		}
+		fmt.Printf("%s:\n", rcpt.Recipient)
+		for _, r := range rcpt.Rules {
+			fmt.Printf("  %s → %s\n", r.Name, strings.Join(r.Actions, ", "))
+		}
+	}
+	return nil
+}
+
+// loadRules validates and compiles the [[rules]] blocks in cfg
+func loadRules(cfg *config.Config) ([]db.Rule, error) {
+	rules := make([]db.Rule, 0, len(cfg.Rules))
+	for i, rc := range cfg.Rules {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		r := db.Rule{
+			Name:        name,
+			From:        rc.From,
+			To:          rc.To,
+			Priority:    rc.Priority,
+			MsgType:     rc.Type,
+			Labels:      rc.Label,
+			Archive:     rc.Archive,
+			MarkRead:    rc.MarkRead,
+			ForwardTo:   rc.ForwardTo,
+			SetPriority: rc.SetPriority,
+			Notify:      rc.Notify,
+		}
+
+		var err error
+		if r.Subject, err = compileRuleRegexp(rc.Subject); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid subject: %w", name, err)
+		}
+		if r.Body, err = compileRuleRegexp(rc.Body); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid body: %w", name, err)
+		}
+		if err := validateRule(&r, cfg); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func compileRuleRegexp(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// validateRule checks a rule's roles, priorities, type and notify set
+func validateRule(r *db.Rule, cfg *config.Config) error {
+	for _, role := range []string{r.From, r.To, r.ForwardTo} {
+		if role != "" && !cfg.IsValidRole(role) {
+			return fmt.Errorf("unknown role: %s (valid roles: %v)", role, cfg.AllRoles())
+		}
+	}
+	for _, p := range []string{r.Priority, r.SetPriority} {
+		if p != "" {
+			if err := validatePriority(p); err != nil {
+				return err
+			}
+		}
+	}
+	if r.MsgType != "" {
+		if err := validateMsgType(r.MsgType); err != nil {
+			return err
+		}
+	}
+	if r.Notify != "" {
+		if _, ok := cfg.Notify[r.Notify]; !ok {
+			return fmt.Errorf("unknown notify command set: %s (add [notify.%s])", r.Notify, r.Notify)
+		}
+	}
+	if len(describeActions(r)) == 0 {
+		return fmt.Errorf("no actions")
+	}
+	return nil
+}
+
+// useRules makes SendMessage on database apply the rules in cfg
+func useRules(database *db.DB, cfg *config.Config) error {
+	rules, err := loadRules(cfg)
+	if err != nil {
+		return err
+	}
+	database.UseRules(rules)
+	return nil
+}
+
+// tryRules is useRules for commands that mostly read mail: invalid rules
+// are reported and left out rather than stopping the command
+func tryRules(database *db.DB, cfg *config.Config) {
+	if err := useRules(database, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: rules not applied: %v (see 'amail rules')\n", err)
+	}
+}
+
+func toRuleJSON(r *db.Rule) RuleJSON {
+	return RuleJSON{
+		Name:    r.Name,
+		Match:   describeConditions(r),
+		Actions: describeActions(r),
+	}
+}
+
+// describeConditions summarizes what a rule matches, e.g.
+// "type=notification subject=/deploy/"
+func describeConditions(r *db.Rule) string {
+	var parts []string
+	for _, c := range []struct{ key, value string }{
+		{"from", r.From}, {"to", r.To}, {"priority", r.Priority}, {"type", r.MsgType},
+	} {
+		if c.value != "" {
+			parts = append(parts, c.key+"="+c.value)
+		}
+	}
+	if r.Subject != nil {
+		parts = append(parts, "subject=/"+r.Subject.String()+"/")
+	}
+	if r.Body != nil {
+		parts = append(parts, "body=/"+r.Body.String()+"/")
+	}
+	if len(parts) == 0 {
+		return "all mail"
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeActions lists what a rule does, in the order it does it
+func describeActions(r *db.Rule) []string {
+	var actions []string
+	for _, l := range r.Labels {
+		actions = append(actions, "label +"+l)
+	}
+	if r.MarkRead {
+		actions = append(actions, "mark read")
+	}
+	if r.Archive {
+		actions = append(actions, "archive")
+	}
+	if r.Notify != "" {
+		actions = append(actions, "notify "+r.Notify)
+	}
+	if r.SetPriority != "" {
+		actions = append(actions, "priority "+r.SetPriority)
+	}
+	if r.ForwardTo != "" {
+		actions = append(actions, "forward to "+r.ForwardTo)
+	}
+	return actions
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+func TestLoadRules(t *testing.T) {
+	newConfig := func(rules ...config.RuleConfig) *config.Config {
+		cfg := config.DefaultConfig()
+		cfg.Agents.Roles = []string{"pm", "dev", "qa"}
+		cfg.Notify["quiet"] = config.NotifyConfig{}
+		cfg.Rules = rules
+		return cfg
+	}
+
+	rules, err := loadRules(newConfig(
+		config.RuleConfig{Name: "fyi", Type: "notification", Label: []string{"fyi"}, MarkRead: true},
+		config.RuleConfig{From: "qa", Subject: "(?i)flaky", ForwardTo: "dev", Notify: "quiet"},
+	))
+	if err != nil {
+		t.Fatalf("loadRules failed: %v", err)
+	}
+	if len(rules) != 2 || rules[1].Name != "rule 2" || rules[1].Subject == nil {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	errorCases := []struct {
+		name string
+		rule config.RuleConfig
+	}{
+		{"no actions", config.RuleConfig{Type: "notification"}},
+		{"bad regex", config.RuleConfig{Subject: "(", Archive: true}},
+		{"unknown role", config.RuleConfig{From: "nobody", Archive: true}},
+		{"unknown forward", config.RuleConfig{ForwardTo: "nobody"}},
+		{"bad priority", config.RuleConfig{SetPriority: "asap"}},
+		{"bad type", config.RuleConfig{Type: "memo", Archive: true}},
+		{"unknown notify set", config.RuleConfig{Notify: "loud"}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := loadRules(newConfig(tc.rule)); err == nil {
+				t.Errorf("loadRules(%+v) expected error", tc.rule)
+			}
+		})
+	}
+}
+
+func TestDescribeRule(t *testing.T) {
+	rules, err := loadRules(&config.Config{
+		Agents: config.AgentsConfig{Roles: []string{"dev", "qa"}},
+		Rules: []config.RuleConfig{
+			{Name: "everything", Archive: true},
+			{Name: "flaky", From: "qa", Type: "notification", Body: "flaky", Label: []string{"ci"}, MarkRead: true, ForwardTo: "dev"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("loadRules failed: %v", err)
+	}
+
+	tests := []struct {
+		rule    db.Rule
+		match   string
+		actions []string
+	}{
+		{rules[0], "all mail", []string{"archive"}},
+		{rules[1], "from=qa type=notification body=/flaky/", []string{"label +ci", "mark read", "forward to dev"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule.Name, func(t *testing.T) {
+			if got := describeConditions(&tt.rule); got != tt.match {
+				t.Errorf("describeConditions() = %q, want %q", got, tt.match)
+			}
+			if got := describeActions(&tt.rule); !reflect.DeepEqual(got, tt.actions) {
+				t.Errorf("describeActions() = %v, want %v", got, tt.actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// ScheduledOutput is the JSON output structure for the scheduled command
+type ScheduledOutput struct {
+	Messages []ScheduledMessageJSON `json:"messages"`
+	Count    int                    `json:"count"`
+}
+
+// ScheduledMessageJSON is the JSON representation of a pending scheduled send
+type ScheduledMessageJSON struct {
+	ID        string   `json:"id"`
+	ShortID   string   `json:"short_id"`
+	To        []string `json:"to"`
+	Subject   string   `json:"subject"`
+	Priority  string   `json:"priority"`
+	Type      string   `json:"type"`
+	DeliverAt string   `json:"deliver_at"`
+}
+
+// ScheduledChangeOutput is the JSON output structure for the scheduled
+// cancel and reschedule commands
+type ScheduledChangeOutput struct {
+	ID        string  `json:"id"`
+	ShortID   string  `json:"short_id"`
+	Cancelled bool    `json:"cancelled,omitempty"`
+	DeliverAt *string `json:"deliver_at,omitempty"`
+}
+
+var scheduledCmd = &cobra.Command{
+	Use:   "scheduled",
+	Short: "List, cancel or reschedule pending sends",
+	Long: `List messages you scheduled with 'amail send --at' or '--in' that have
+not been delivered yet.
+
+Examples:
+  amail scheduled
+  amail scheduled cancel abc123
+  amail scheduled reschedule abc123 --at "2026-10-17T09:00"
+  amail scheduled reschedule abc123 --in 30m`,
+	Args: cobra.NoArgs,
+	RunE: runScheduled,
+}
+
+var scheduledCancelCmd = &cobra.Command{
+	Use:   "cancel <message-id>",
+	Short: "Cancel a scheduled send",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runScheduledCancel,
+}
+
+var scheduledRescheduleCmd = &cobra.Command{
+	Use:   "reschedule <message-id>",
+	Short: "Change when a scheduled send is delivered",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runScheduledReschedule,
+}
+
+var (
+	rescheduleAt string
+	rescheduleIn string
+)
+
+func init() {
+	scheduledRescheduleCmd.Flags().StringVar(&rescheduleAt, "at", "", "Deliver at a date/time (YYYY-MM-DDTHH:MM or RFC3339)")
+	scheduledRescheduleCmd.Flags().StringVar(&rescheduleIn, "in", "", "Deliver after a delay from now (e.g. 30m, 2h, 1d)")
+	scheduledCmd.AddCommand(scheduledCancelCmd)
+	scheduledCmd.AddCommand(scheduledRescheduleCmd)
+	rootCmd.AddCommand(scheduledCmd)
+}
+
+func runScheduled(cmd *cobra.Command, args []string) error {
+	database, fromID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	scheduled, err := database.GetScheduled(fromID)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled messages: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := ScheduledOutput{
+			Messages: make([]ScheduledMessageJSON, len(scheduled)),
+			Count:    len(scheduled),
+		}
+		for i, m := range scheduled {
+			output.Messages[i] = ScheduledMessageJSON{
+				ID:        m.ID,
+				ShortID:   SafeShortID(m.ID),
+				To:        m.ToIDs,
+				Subject:   m.Subject,
+				Priority:  m.Priority,
+				Type:      m.MsgType,
+				DeliverAt: m.DeliverAt.Local().Format(time.RFC3339),
+			}
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(scheduled) == 0 {
+		fmt.Println("No scheduled messages.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTO\tSUBJECT\tDELIVER AT")
+	fmt.Fprintln(w, "--\t--\t-------\t----------")
+	for _, m := range scheduled {
+		subject := m.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		until := "<1 min"
+		if d := time.Until(m.DeliverAt); d >= time.Minute {
+			until = formatDuration(d)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s (in %s)\n",
+			SafeShortID(m.ID), truncate(strings.Join(m.ToIDs, ","), 20), truncate(subject, 30),
+			m.DeliverAt.Local().Format("2006-01-02 15:04"), until)
+	}
+	w.Flush()
+
+	return nil
+}
+
+func runScheduledCancel(cmd *cobra.Command, args []string) error {
+	database, fromID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	msg, err := findScheduled(database, args[0], fromID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := database.CancelScheduled(msg.ID, fromID)
+	if err != nil && !ok {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("message %s has already been delivered", SafeShortID(msg.ID))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(ScheduledChangeOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), Cancelled: true})
+	}
+
+	// Text output
+	fmt.Printf("✓ Cancelled %s\n", SafeShortID(msg.ID))
+	return nil
+}
+
+func runScheduledReschedule(cmd *cobra.Command, args []string) error {
+	deliverAt, err := parseDeliverAt(rescheduleAt, rescheduleIn, time.Now())
+	if err != nil {
+		return err
+	}
+	if deliverAt == nil {
+		return fmt.Errorf("new delivery time required (--at or --in)")
+	}
+
+	database, fromID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	msg, err := findScheduled(database, args[0], fromID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := database.Reschedule(msg.ID, fromID, *deliverAt)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("message %s has already been delivered", SafeShortID(msg.ID))
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		at := deliverAt.Format(time.RFC3339)
+		return PrintJSON(ScheduledChangeOutput{ID: msg.ID, ShortID: SafeShortID(msg.ID), DeliverAt: &at})
+	}
+
+	// Text output
+	fmt.Printf("✓ Rescheduled %s for %s\n", SafeShortID(msg.ID), deliverAt.Format("2006-01-02 15:04"))
+	return nil
+}
+
+// findScheduled finds one of fromID's pending messages by ID prefix
+func findScheduled(database *db.DB, prefix, fromID string) (*db.ScheduledMessage, error) {
+	scheduled, err := database.GetScheduled(fromID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled messages: %w", err)
+	}
+
+	var matches []*db.ScheduledMessage
+	for i := range scheduled {
+		if strings.HasPrefix(scheduled[i].ID, prefix) {
+			matches = append(matches, &scheduled[i])
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no scheduled message: %s", prefix)
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("ambiguous ID prefix: %s matches %d messages", prefix, len(matches))
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// SearchOutput is the JSON output structure for the search command
+type SearchOutput struct {
+	Query   string             `json:"query"`
+	Results []SearchResultJSON `json:"results"`
+	Count   int                `json:"count"`
+}
+
+// SearchResultJSON is the JSON representation of a search result
+type SearchResultJSON struct {
+	ID        string   `json:"id"`
+	ShortID   string   `json:"short_id"`
+	From      string   `json:"from"`
+	To        []string `json:"to"`
+	Subject   string   `json:"subject"`
+	Snippet   string   `json:"snippet"`
+	Priority  string   `json:"priority"`
+	Type      string   `json:"type"`
+	Score     float64  `json:"score"`
+	ThreadID  *string  `json:"thread_id,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search messages you sent or received",
+	Long: `Full-text search over message subjects and bodies.
+
+Only messages you sent or received are searched. Results are ranked by
+relevance, with subject matches weighing more than body matches.
+
+All terms must match. A trailing * matches a prefix. Use --raw to pass
+SQLite FTS5 query syntax (OR, NOT, NEAR, "phrases") through unchanged.
+
+Examples:
+  amail search "auth endpoint"
+  amail search deploy --from pm --since 7d
+  amail search migrat* --to qa
+  amail search --raw 'login OR signup'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+var (
+	searchFrom  string
+	searchTo    string
+	searchSince string
+	searchLimit int
+	searchRaw   bool
+)
+
+func init() {
+	searchCmd.Flags().StringVar(&searchFrom, "from", "", "Only messages from this sender")
+	searchCmd.Flags().StringVar(&searchTo, "to", "", "Only messages to this recipient")
+	searchCmd.Flags().StringVar(&searchSince, "since", "", "Only messages since a duration ago (24h, 7d) or a date")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results")
+	searchCmd.Flags().BoolVar(&searchRaw, "raw", false, "Pass the query to FTS5 unchanged")
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
+
+	opts := db.SearchOptions{
+		Query: query,
+		Raw:   searchRaw,
+		From:  searchFrom,
+		To:    searchTo,
+		Limit: searchLimit,
+	}
+	if searchSince != "" {
+		since, err := parseSince(searchSince, time.Now())
+		if err != nil {
+			return err
+		}
+		opts.Since = since
+	}
+
+	// Highlight matches: markdown-style in JSON, bold in the terminal
+	jsonOutput := IsJSONOutput()
+	if jsonOutput {
+		opts.HighlightStart, opts.HighlightEnd = "**", "**"
+	} else {
+		opts.HighlightStart, opts.HighlightEnd = "\033[1m", "\033[0m"
+	}
+
+	results, err := database.Search(res.Identity, opts)
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if jsonOutput {
+		output := SearchOutput{
+			Query:   query,
+			Results: make([]SearchResultJSON, len(results)),
+			Count:   len(results),
+		}
+		for i, r := range results {
+			output.Results[i] = SearchResultJSON{
+				ID:        r.ID,
+				ShortID:   SafeShortID(r.ID),
+				From:      r.FromID,
+				To:        r.ToIDs,
+				Subject:   r.Subject,
+				Snippet:   r.Snippet,
+				Priority:  r.Priority,
+				Type:      r.MsgType,
+				Score:     r.Score,
+				ThreadID:  r.ThreadID,
+				CreatedAt: r.CreatedAt.Format(time.RFC3339),
+			}
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(results) == 0 {
+		fmt.Println("No matching messages.")
+		return nil
+	}
+
+	for _, r := range results {
+		subject := r.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		fmt.Printf("[%s] %s → %s: %s (%s)\n",
+			SafeShortID(r.ID), r.FromID, strings.Join(r.ToIDs, ","), subject, formatTimeAgo(r.CreatedAt))
+		fmt.Printf("    %s\n", strings.ReplaceAll(r.Snippet, "\n", " "))
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -13,9 +14,13 @@ import (
 
 // SendOutput is the JSON output structure for the send command
 type SendOutput struct {
-	ID         string   `json:"id"`
-	ShortID    string   `json:"short_id"`
-	Recipients []string `json:"recipients"`
+	ID          string           `json:"id"`
+	ShortID     string           `json:"short_id"`
+	Recipients  []string         `json:"recipients"`
+	Attachments []AttachmentJSON `json:"attachments,omitempty"`
+	DeliverAt   *string          `json:"deliver_at,omitempty"`
+	DueAt       *string          `json:"due_at,omitempty"`
+	Warnings    []string         `json:"warnings,omitempty"`
 }
 
 var sendCmd = &cobra.Command{
@@ -28,12 +33,29 @@ Recipients can be:
   - Multiple: dev,qa,pm
   - Groups: @all, @agents, @others, or custom groups from config
 
+With --at or --in, the message is queued and only becomes visible to
+recipients at that time. Use 'amail scheduled' to list, cancel or
+reschedule pending sends.
+
+Requests can carry a deadline with --due, either a delay from delivery
+(4h, 1d) or a date/time. Overdue requests are flagged in inbox and tasks,
+and 'amail watch' escalates them to the sender.
+
+If a recipient has not run amail for longer than the offline threshold
+in [presence], send warns. With --fallback, the message goes to the
+fallback role instead. Roles never seen are not checked.
+
 Examples:
   amail send dev "API ready" "GET /users endpoint at routes/users.ts:45"
   amail send dev,qa "Ready for review" "Feature complete"
   amail send @all "Announcement" "Deploy at 3pm"
   amail send dev -p urgent "Bug found" "Production issue"
-  amail send pm -t request "Need spec" "Please clarify requirements"`,
+  amail send pm -t request "Need spec" "Please clarify requirements"
+  amail send qa "Test failures" "See attached log" --attach test.log
+  amail send qa --at "2026-10-17T09:00" "Handoff" "Picking up from here"
+  amail send dev --in 2h "Reminder" "Check the deploy"
+  amail send dev -t request --due 4h "Review PR" "Before the release cut"
+  amail send dev --fallback qa "Hotfix" "Whoever is around, please look"`,
 	Args: cobra.ExactArgs(3),
 	RunE: runSend,
 }
@@ -41,11 +63,21 @@ Examples:
 var (
 	sendPriority string
 	sendType     string
+	sendAttach   []string
+	sendAt       string
+	sendIn       string
+	sendDue      string
+	sendFallback string
 )
 
 func init() {
 	sendCmd.Flags().StringVarP(&sendPriority, "priority", "p", "normal", "Priority: low, normal, high, urgent")
 	sendCmd.Flags().StringVarP(&sendType, "type", "t", "message", "Type: message, request, response, notification")
+	sendCmd.Flags().StringArrayVarP(&sendAttach, "attach", "a", nil, "Attach a file (repeatable)")
+	sendCmd.Flags().StringVar(&sendAt, "at", "", "Deliver at a date/time (YYYY-MM-DDTHH:MM or RFC3339)")
+	sendCmd.Flags().StringVar(&sendIn, "in", "", "Deliver after a delay (e.g. 30m, 2h, 1d)")
+	sendCmd.Flags().StringVar(&sendDue, "due", "", "Deadline for a request: delay after delivery (e.g. 4h) or date/time")
+	sendCmd.Flags().StringVar(&sendFallback, "fallback", "", "Send to this role instead of recipients who are offline")
 	rootCmd.AddCommand(sendCmd)
 }
 
@@ -60,6 +92,15 @@ func runSend(cmd *cobra.Command, args []string) error {
 	if err := validateMsgType(sendType); err != nil {
 		return err
 	}
+	now := time.Now()
+	deliverAt, err := parseDeliverAt(sendAt, sendIn, now)
+	if err != nil {
+		return err
+	}
+	dueAt, err := parseDue(sendDue, sendType, deliverAt, now)
+	if err != nil {
+		return err
+	}
 
 	// Open project
 	database, root, err := db.OpenProject()
@@ -73,39 +114,60 @@ func runSend(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := useRules(database, cfg); err != nil {
+		return err
+	}
 
 	// Resolve sender identity
 	res, err := identity.MustResolve(cfg)
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	fromID := res.Identity
 
 	// Resolve recipients
-	recipients, err := resolveRecipients(toArg, fromID, cfg)
+	recipients, err := resolveSendRecipients(toArg, fromID, cfg)
 	if err != nil {
 		return err
 	}
 
-	if len(recipients) == 0 {
-		return fmt.Errorf("no recipients resolved")
+	// Scheduled messages arrive later, so whether anyone is online now
+	// does not matter
+	var warnings []string
+	if deliverAt == nil {
+		recipients, warnings, err = rerouteOffline(database, cfg, recipients, fromID, sendFallback, now)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Remove sender from recipients (can't send to self)
-	recipients = filterOut(recipients, fromID)
-	if len(recipients) == 0 {
-		return fmt.Errorf("cannot send to self only")
+	// Store attachments
+	attachments, err := storeAttachments(database, sendAttach)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled messages are dated by when recipients receive them
+	createdAt := now
+	if deliverAt != nil {
+		createdAt = *deliverAt
 	}
 
 	// Create message
 	msg := &db.Message{
-		ID:        generateID(),
-		FromID:    fromID,
-		Subject:   subject,
-		Body:      body,
-		Priority:  sendPriority,
-		MsgType:   sendType,
-		CreatedAt: time.Now(),
+		ID:          db.NewID(),
+		FromID:      fromID,
+		Subject:     subject,
+		Body:        body,
+		Priority:    sendPriority,
+		MsgType:     sendType,
+		CreatedAt:   createdAt,
+		DeliverAt:   deliverAt,
+		DueAt:       dueAt,
+		Attachments: attachments,
 	}
 
 	// Send
@@ -116,19 +178,104 @@ func runSend(cmd *cobra.Command, args []string) error {
 	// JSON output
 	if IsJSONOutput() {
 		output := SendOutput{
-			ID:         msg.ID,
-			ShortID:    SafeShortID(msg.ID),
-			Recipients: recipients,
+			ID:          msg.ID,
+			ShortID:     SafeShortID(msg.ID),
+			Recipients:  recipients,
+			Attachments: toAttachmentsJSON(attachments),
+			Warnings:    warnings,
 		}
+		output.DeliverAt = formatOptionalTime(deliverAt)
+		output.DueAt = formatOptionalTime(dueAt)
 		return PrintJSON(output)
 	}
 
 	// Text output
-	fmt.Printf("✓ Sent %s to: %s\n", msg.ID, strings.Join(recipients, ", "))
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", w)
+	}
+	if deliverAt != nil {
+		fmt.Printf("✓ Scheduled %s to: %s at %s\n", msg.ID, strings.Join(recipients, ", "), deliverAt.Format("2006-01-02 15:04"))
+	} else {
+		fmt.Printf("✓ Sent %s to: %s\n", msg.ID, strings.Join(recipients, ", "))
+	}
+	if dueAt != nil {
+		fmt.Printf("  Due %s\n", dueAt.Format("2006-01-02 15:04"))
+	}
+	for _, a := range attachments {
+		fmt.Printf("  📎 %s (%s)\n", a.Name, formatSize(a.Size))
+	}
 
 	return nil
 }
 
+// resolveSendRecipients resolves the recipients of a new message, excluding
+// the sender
+func resolveSendRecipients(toArg, fromID string, cfg *config.Config) ([]string, error) {
+	recipients, err := resolveRecipients(toArg, fromID, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipients resolved")
+	}
+
+	// Remove sender from recipients (can't send to self)
+	recipients = filterOut(recipients, fromID)
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("cannot send to self only")
+	}
+
+	return recipients, nil
+}
+
+// rerouteOffline checks recipients against the presence registry. Roles
+// offline longer than the configured threshold are replaced by fallback,
+// or just warned about if there is none. The user is never checked.
+func rerouteOffline(database *db.DB, cfg *config.Config, recipients []string, fromID, fallback string, now time.Time) ([]string, []string, error) {
+	if fallback != "" && !cfg.IsValidRole(fallback) {
+		return nil, nil, fmt.Errorf("unknown fallback: %s (valid roles: %v)", fallback, cfg.AllRoles())
+	}
+
+	var result, warnings []string
+	seen := make(map[string]bool)
+	add := func(role string) {
+		if !seen[role] && role != fromID {
+			seen[role] = true
+			result = append(result, role)
+		}
+	}
+
+	for _, r := range recipients {
+		if r == "user" {
+			add(r)
+			continue
+		}
+		lastSeen, err := database.LastSeen(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lastSeen == nil || presenceState(lastSeen, now, cfg) != PresenceOffline {
+			add(r)
+			continue
+		}
+
+		ago := formatTimeAgo(*lastSeen)
+		if fallback == "" || fallback == r {
+			warnings = append(warnings, fmt.Sprintf("%s is offline (last seen %s)", r, ago))
+			add(r)
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("%s is offline (last seen %s), sending to %s instead", r, ago, fallback))
+		add(fallback)
+	}
+
+	if len(result) == 0 {
+		return nil, nil, fmt.Errorf("cannot send to self only")
+	}
+	return result, warnings, nil
+}
+
 // resolveRecipients resolves a recipient string to a list of role IDs
 func resolveRecipients(toArg, fromID string, cfg *config.Config) ([]string, error) {
 	var allRecipients []string
@@ -147,3 +147,20 @@ func TestFilterOut(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveSendRecipients(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Roles = []string{"pm", "dev", "qa"}
+
+	got, err := resolveSendRecipients("dev,pm", "pm", cfg)
+	if err != nil {
+		t.Fatalf("resolveSendRecipients failed: %v", err)
+	}
+	if len(got) != 1 || got[0] != "dev" {
+		t.Errorf("expected sender to be removed, got %v", got)
+	}
+
+	if _, err := resolveSendRecipients("pm", "pm", cfg); err == nil {
+		t.Error("expected error when sending only to self")
+	}
+}
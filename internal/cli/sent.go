@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+var sentCmd = &cobra.Command{
+	Use:   "sent",
+	Short: "List messages you have sent",
+	Long: `List messages you have sent, with per-recipient read status.
+
+A * before the ID means at least one recipient has not read the message.
+In the TO column, a * after a recipient means that recipient hasn't read it.
+
+Examples:
+  amail sent
+  amail sent --to dev    # Only messages sent to dev
+  amail sent --unread    # Only messages someone hasn't read yet`,
+	RunE: runSent,
+}
+
+var (
+	sentTo     string
+	sentUnread bool
+)
+
+func init() {
+	sentCmd.Flags().StringVar(&sentTo, "to", "", "Filter by recipient")
+	sentCmd.Flags().BoolVar(&sentUnread, "unread", false, "Only messages not yet read by every recipient")
+	rootCmd.AddCommand(sentCmd)
+}
+
+func runSent(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
+	fromID := res.Identity
+
+	// Get messages
+	sent, err := database.GetSent(fromID)
+	if err != nil {
+		return fmt.Errorf("failed to get sent messages: %w", err)
+	}
+
+	// Apply filters
+	var filtered []db.SentMessage
+	for _, m := range sent {
+		if sentTo != "" && !containsString(m.ToIDs, sentTo) {
+			continue
+		}
+		if sentUnread && m.Status != "unread" {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	sent = filtered
+
+	// JSON output
+	if IsJSONOutput() {
+		output := InboxOutput{
+			Messages: make([]InboxMessageJSON, len(sent)),
+			Count:    len(sent),
+		}
+		for i := range sent {
+			output.Messages[i] = toInboxMessageJSON(&sent[i].InboxMessage)
+			output.Messages[i].Recipients = toRecipientStatusJSON(sent[i].Recipients)
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(sent) == 0 {
+		fmt.Println("No sent messages.")
+		return nil
+	}
+
+	messages := make([]db.InboxMessage, len(sent))
+	recipients := make(map[string][]db.Recipient, len(sent))
+	for i, m := range sent {
+		messages[i] = m.InboxMessage
+		recipients[m.ID] = m.Recipients
+	}
+
+	printMessageTable(messages, func(m *db.InboxMessage) string {
+		return formatRecipientStatus(recipients[m.ID])
+	})
+
+	return nil
+}
+
+// formatRecipientStatus renders recipients with a * after each one who
+// hasn't read the message yet
+func formatRecipientStatus(recipients []db.Recipient) string {
+	parts := make([]string, len(recipients))
+	for i, r := range recipients {
+		parts[i] = r.ToID
+		if r.Status == "unread" {
+			parts[i] += "*"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// containsString reports whether slice contains value
+func containsString(slice []string, value string) bool {
+	for _, s := range slice {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
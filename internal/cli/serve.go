@@ -0,0 +1,612 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// SendRequest is the JSON body for sending a message over the API
+type SendRequest struct {
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	Priority string `json:"priority,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+// ReplyRequest is the JSON body for replying to a message over the API
+type ReplyRequest struct {
+	Body     string `json:"body"`
+	All      bool   `json:"all,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+// MessageActionOutput is the JSON output of mark-read, archive and delete
+type MessageActionOutput struct {
+	ID      string `json:"id"`
+	ShortID string `json:"short_id"`
+	Status  string `json:"status"`
+}
+
+// MarkAllReadOutput is the JSON output of marking every message as read
+type MarkAllReadOutput struct {
+	Count int64 `json:"count"`
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the mailbox over an HTTP/JSON API",
+	Long: `Serve the mailbox over an HTTP/JSON API for tools that aren't shells.
+
+Every request must carry a bearer token that identifies its role:
+  Authorization: Bearer <token>
+
+Configure one token per role in .amail/config.toml:
+  [serve.tokens]
+  dev = "..."
+
+Responses use the same {"success", "data", "error"} envelope as --json.
+
+Endpoints:
+  GET    /api/inbox                   List inbox (?all=true, ?from=role)
+  GET    /api/inbox/stream            Server-sent events for new mail
+  POST   /api/inbox/mark-read         Mark all messages as read
+  POST   /api/messages                Send {to, subject, body, priority, type}
+  GET    /api/messages/{id}           Read a message (marks it read)
+  POST   /api/messages/{id}/reply     Reply {body, all, priority, type}
+  GET    /api/messages/{id}/thread    View the message's thread
+  POST   /api/messages/{id}/mark-read Mark as read
+  POST   /api/messages/{id}/archive   Archive
+  DELETE /api/messages/{id}           Delete from inbox
+  GET    /api/stats                   Message statistics
+
+Examples:
+  amail serve
+  amail serve --listen 127.0.0.1:8080`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var serveListen string
+
+// maxRequestBody limits the size of API request bodies
+const maxRequestBody = 1 << 20
+
+// sseKeepAlive is how often an idle event stream sends a comment line
+const sseKeepAlive = 30 * time.Second
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "", "Address to listen on (default from config)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	tryRules(database, cfg)
+
+	if len(cfg.Serve.Tokens) == 0 {
+		return fmt.Errorf("no API tokens configured; add a [serve.tokens] section to %s", config.ConfigPath(root))
+	}
+
+	listen := cfg.Serve.Listen
+	if serveListen != "" {
+		listen = serveListen
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           newAPIServer(database, cfg).routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Shut down cleanly on Ctrl+C
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving amail API on http://%s\n", listen)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return nil
+}
+
+// apiServer serves the mailbox over HTTP
+type apiServer struct {
+	db  *db.DB
+	cfg *config.Config
+
+	// Stream clients share one change subscription while any are connected
+	mu          sync.Mutex
+	streams     map[chan struct{}]bool
+	unsubscribe context.CancelFunc
+}
+
+// roleHandler handles an authenticated request on behalf of a role
+type roleHandler func(w http.ResponseWriter, r *http.Request, role string)
+
+func newAPIServer(database *db.DB, cfg *config.Config) *apiServer {
+	return &apiServer{db: database, cfg: cfg}
+}
+
+// routes returns the API's request router
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/inbox", s.auth(s.handleInbox))
+	mux.HandleFunc("GET /api/inbox/stream", s.auth(s.handleStream))
+	mux.HandleFunc("POST /api/inbox/mark-read", s.auth(s.handleMarkAllRead))
+	mux.HandleFunc("POST /api/messages", s.auth(s.handleSend))
+	mux.HandleFunc("GET /api/messages/{id}", s.auth(s.handleRead))
+	mux.HandleFunc("POST /api/messages/{id}/reply", s.auth(s.handleReply))
+	mux.HandleFunc("GET /api/messages/{id}/thread", s.auth(s.handleThread))
+	mux.HandleFunc("POST /api/messages/{id}/mark-read", s.auth(s.handleMarkRead))
+	mux.HandleFunc("POST /api/messages/{id}/archive", s.auth(s.handleArchive))
+	mux.HandleFunc("POST /api/messages/{id}/unarchive", s.auth(s.handleUnarchive))
+	mux.HandleFunc("DELETE /api/messages/{id}", s.auth(s.handleDelete))
+	mux.HandleFunc("GET /api/stats", s.auth(s.handleStats))
+	return mux
+}
+
+// auth resolves the caller's role from its bearer token. EventSource clients
+// can't set headers, so an access_token query parameter is also accepted.
+func (s *apiServer) auth(next roleHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("access_token")
+		if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+			token = strings.TrimPrefix(h, "Bearer ")
+		}
+
+		role := s.cfg.RoleForToken(token)
+		if role == "" {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next(w, r, role)
+	}
+}
+
+func (s *apiServer) handleInbox(w http.ResponseWriter, r *http.Request, role string) {
+	all, _ := strconv.ParseBool(r.URL.Query().Get("all"))
+	archived, _ := strconv.ParseBool(r.URL.Query().Get("archived"))
+	from := r.URL.Query().Get("from")
+
+	statuses := []string{db.StatusUnread}
+	if archived {
+		statuses = []string{db.StatusArchived}
+	} else if all {
+		statuses = append(statuses, db.StatusRead)
+	}
+
+	messages, err := s.db.GetInbox(role, statuses...)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to get inbox: %w", err))
+		return
+	}
+
+	output := InboxOutput{Messages: []InboxMessageJSON{}}
+	for i := range messages {
+		if from != "" && messages[i].FromID != from {
+			continue
+		}
+		output.Messages = append(output.Messages, toInboxMessageJSON(&messages[i]))
+	}
+	output.Count = len(output.Messages)
+
+	writeAPIResponse(w, http.StatusOK, output)
+}
+
+func (s *apiServer) handleSend(w http.ResponseWriter, r *http.Request, role string) {
+	req := SendRequest{Priority: "normal", Type: "message"}
+	if !decodeAPIRequest(w, r, &req) {
+		return
+	}
+
+	if err := validatePriority(req.Priority); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+	if err := validateMsgType(req.Type); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+
+	recipients, err := resolveSendRecipients(req.To, role, s.cfg)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+
+	msg := &db.Message{
+		ID:        db.NewID(),
+		FromID:    role,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		Priority:  req.Priority,
+		MsgType:   req.Type,
+		CreatedAt: time.Now(),
+	}
+	if err := s.db.SendMessage(msg, recipients); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to send message: %w", err))
+		return
+	}
+
+	writeAPIResponse(w, http.StatusCreated, SendOutput{
+		ID:         msg.ID,
+		ShortID:    SafeShortID(msg.ID),
+		Recipients: recipients,
+	})
+}
+
+func (s *apiServer) handleRead(w http.ResponseWriter, r *http.Request, role string) {
+	msg, ok := s.findInboxMessage(w, r, role)
+	if !ok {
+		return
+	}
+
+	if msg.Status == "unread" {
+		if err := s.db.MarkRead(msg.ID, role); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to mark as read: %w", err))
+			return
+		}
+		msg.Status = "read"
+	}
+
+	writeAPIResponse(w, http.StatusOK, toReadOutput(msg))
+}
+
+func (s *apiServer) handleReply(w http.ResponseWriter, r *http.Request, role string) {
+	req := ReplyRequest{Priority: "normal", Type: "response"}
+	if !decodeAPIRequest(w, r, &req) {
+		return
+	}
+
+	if err := validatePriority(req.Priority); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+	if err := validateMsgType(req.Type); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+
+	msg, recipients, err := newReply(s.db, role, r.PathValue("id"), req.All, req.Body, req.Priority, req.Type)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return
+	}
+	if err := s.db.SendMessage(msg, recipients); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to send reply: %w", err))
+		return
+	}
+
+	writeAPIResponse(w, http.StatusCreated, ReplyOutput{
+		ID:         msg.ID,
+		ShortID:    SafeShortID(msg.ID),
+		ThreadID:   *msg.ThreadID,
+		Recipients: recipients,
+	})
+}
+
+func (s *apiServer) handleThread(w http.ResponseWriter, r *http.Request, role string) {
+	msg, err := findAnyMessage(s.db, r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "not_found", err)
+		return
+	}
+
+	threadID := threadRoot(msg)
+	messages, err := s.db.GetThread(threadID, role)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to get thread: %w", err))
+		return
+	}
+
+	// Only threads the caller wrote to or received mail in
+	if !inThread(messages, role) {
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Errorf("message not found: %s", r.PathValue("id")))
+		return
+	}
+
+	writeAPIResponse(w, http.StatusOK, toThreadOutput(threadID, messages))
+}
+
+func (s *apiServer) handleMarkRead(w http.ResponseWriter, r *http.Request, role string) {
+	s.messageAction(w, r, role, "read", s.db.MarkRead)
+}
+
+func (s *apiServer) handleArchive(w http.ResponseWriter, r *http.Request, role string) {
+	s.messageAction(w, r, role, "archived", s.db.Archive)
+}
+
+func (s *apiServer) handleUnarchive(w http.ResponseWriter, r *http.Request, role string) {
+	msg, ok := s.findInboxMessage(w, r, role)
+	if !ok {
+		return
+	}
+
+	ok, err := s.db.Unarchive(msg.ID, role)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to update message: %w", err))
+		return
+	}
+	if !ok {
+		writeAPIError(w, http.StatusConflict, "conflict", fmt.Errorf("message %s is not archived", SafeShortID(msg.ID)))
+		return
+	}
+
+	writeAPIResponse(w, http.StatusOK, MessageActionOutput{
+		ID:      msg.ID,
+		ShortID: SafeShortID(msg.ID),
+		Status:  db.StatusRead,
+	})
+}
+
+func (s *apiServer) handleDelete(w http.ResponseWriter, r *http.Request, role string) {
+	s.messageAction(w, r, role, db.StatusTrashed, s.db.Trash)
+}
+
+// messageAction applies a status change to a message in the role's inbox
+func (s *apiServer) messageAction(w http.ResponseWriter, r *http.Request, role, status string, apply func(messageID, toID string) error) {
+	msg, ok := s.findInboxMessage(w, r, role)
+	if !ok {
+		return
+	}
+
+	if err := apply(msg.ID, role); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to update message: %w", err))
+		return
+	}
+
+	writeAPIResponse(w, http.StatusOK, MessageActionOutput{
+		ID:      msg.ID,
+		ShortID: SafeShortID(msg.ID),
+		Status:  status,
+	})
+}
+
+func (s *apiServer) handleMarkAllRead(w http.ResponseWriter, r *http.Request, role string) {
+	count, err := s.db.MarkAllRead(role)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("failed to mark all as read: %w", err))
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, MarkAllReadOutput{Count: count})
+}
+
+// handleStats counts the role's own mail; user sees every role's
+func (s *apiServer) handleStats(w http.ResponseWriter, r *http.Request, role string) {
+	roles := []string{role}
+	if role == "user" {
+		roles = s.cfg.AllRoles()
+	}
+	writeAPIResponse(w, http.StatusOK, collectStats(s.db, roles))
+}
+
+// subscribe returns a channel signalled whenever the mailbox changes, and a
+// function to stop. The first stream client starts the server's change
+// subscription and the last one to leave stops it.
+func (s *apiServer) subscribe() (<-chan struct{}, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.streams == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		changes, err := s.db.Subscribe(ctx, pollInterval(s.cfg, 0))
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		s.streams = make(map[chan struct{}]bool)
+		s.unsubscribe = cancel
+		go s.broadcast(changes)
+	}
+
+	ch := make(chan struct{}, 1)
+	s.streams[ch] = true
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.streams, ch)
+		if len(s.streams) == 0 && s.unsubscribe != nil {
+			s.unsubscribe()
+			s.streams = nil
+			s.unsubscribe = nil
+		}
+	}, nil
+}
+
+// broadcast signals every stream client on each change, without blocking
+// on slow ones
+func (s *apiServer) broadcast(changes <-chan int64) {
+	for range changes {
+		s.mu.Lock()
+		for ch := range s.streams {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// handleStream sends each new message for the role as a server-sent event,
+// as its inbox shows it: mail the inbox hides, e.g. recalled, archived by a
+// rule or claimed by another session, is skipped. Event IDs are event log
+// sequence numbers, so clients resume with Last-Event-ID after reconnecting.
+func (s *apiServer) handleStream(w http.ResponseWriter, r *http.Request, role string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "", fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	// Subscribe before reading the cursor so nothing in between is missed
+	changes, unsubscribe, err := s.subscribe()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "", err)
+		return
+	}
+	defer unsubscribe()
+
+	cursor, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64)
+	if err != nil {
+		cursor, err = s.db.LatestEventSeq()
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "", err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		// Catch up first, so a resumed stream doesn't wait for a change
+		if cursor, err = s.streamMessages(w, role, cursor); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-changes:
+		}
+	}
+}
+
+// streamMessages writes an event for each message sent to role after
+// cursor that is unread in its inbox, and returns the new cursor
+func (s *apiServer) streamMessages(w http.ResponseWriter, role string, cursor int64) (int64, error) {
+	events, err := s.db.GetEvents(cursor, 0)
+	if err != nil {
+		return cursor, err
+	}
+
+	var inbox map[string]*db.InboxMessage
+	for _, e := range events {
+		cursor = e.Seq
+		if e.Type != "sent" {
+			continue
+		}
+
+		// Load the inbox once per batch, only if something was sent
+		if inbox == nil {
+			messages, err := s.db.GetInbox(role, db.StatusUnread)
+			if err != nil {
+				return cursor, err
+			}
+			inbox = make(map[string]*db.InboxMessage, len(messages))
+			for i := range messages {
+				inbox[messages[i].ID] = &messages[i]
+			}
+		}
+		msg, ok := inbox[e.MessageID]
+		if !ok {
+			continue
+		}
+
+		data, err := json.Marshal(toInboxMessageJSON(msg))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", e.Seq, data)
+	}
+	return cursor, nil
+}
+
+// findInboxMessage looks up the {id} path value in the role's inbox,
+// writing an error response if it can't be found
+func (s *apiServer) findInboxMessage(w http.ResponseWriter, r *http.Request, role string) (*db.InboxMessage, bool) {
+	id := r.PathValue("id")
+	msg, err := findMessageByPrefix(s.db, id, role)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", err)
+		return nil, false
+	}
+	if msg == nil {
+		writeAPIError(w, http.StatusNotFound, "not_found", fmt.Errorf("message not found: %s", id))
+		return nil, false
+	}
+	return msg, true
+}
+
+// inThread reports whether role sent or received any of a thread's messages
+func inThread(messages []db.InboxMessage, role string) bool {
+	for _, m := range messages {
+		if m.FromID == role || containsString(m.ToIDs, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeAPIRequest decodes a JSON request body into v, writing an error
+// response if it is invalid
+func decodeAPIRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad_request", fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeAPIResponse writes data in the standard JSON envelope
+func writeAPIResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response{Success: true, Data: data})
+}
+
+// writeAPIError writes an error in the standard JSON envelope
+func writeAPIError(w http.ResponseWriter, status int, code string, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response{
+		Success: false,
+		Error:   &ErrorInfo{Message: err.Error(), Code: code},
+	})
+}
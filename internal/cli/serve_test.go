@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+func setupTestServer(t *testing.T) (*httptest.Server, *db.DB) {
+	t.Helper()
+
+	database, err := db.Open(filepath.Join(t.TempDir(), "mail.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Roles = []string{"pm", "dev", "qa"}
+	cfg.Serve.Tokens = map[string]string{"pm": "pm-token", "dev": "dev-token"}
+
+	srv := httptest.NewServer(newAPIServer(database, cfg).routes())
+	t.Cleanup(srv.Close)
+	return srv, database
+}
+
+// apiCall makes an authenticated request and decodes the envelope's data into out
+func apiCall(t *testing.T, srv *httptest.Server, method, path, token string, body interface{}, out interface{}) int {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, _ := http.NewRequest(method, srv.URL+path, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   *ErrorInfo      `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("%s %s: invalid envelope: %v", method, path, err)
+	}
+	if envelope.Success != (resp.StatusCode < 400) {
+		t.Errorf("%s %s: success=%v with status %d", method, path, envelope.Success, resp.StatusCode)
+	}
+	if out != nil && envelope.Data != nil {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			t.Fatalf("%s %s: invalid data: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServeRequiresToken(t *testing.T) {
+	srv, _ := setupTestServer(t)
+
+	if code := apiCall(t, srv, "GET", "/api/inbox", "", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", code)
+	}
+	if code := apiCall(t, srv, "GET", "/api/inbox", "wrong", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with bad token, got %d", code)
+	}
+}
+
+func TestServeSendReadReply(t *testing.T) {
+	srv, database := setupTestServer(t)
+
+	// pm sends to dev
+	var sent SendOutput
+	code := apiCall(t, srv, "POST", "/api/messages", "pm-token",
+		SendRequest{To: "dev", Subject: "Spec", Body: "Please build it"}, &sent)
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201 from send, got %d", code)
+	}
+
+	// The token decides identity: dev sees it, pm doesn't
+	var inbox InboxOutput
+	apiCall(t, srv, "GET", "/api/inbox", "dev-token", nil, &inbox)
+	if inbox.Count != 1 || inbox.Messages[0].ID != sent.ID {
+		t.Fatalf("expected dev to have the message, got %+v", inbox)
+	}
+	apiCall(t, srv, "GET", "/api/inbox", "pm-token", nil, &inbox)
+	if inbox.Count != 0 {
+		t.Errorf("expected pm inbox to be empty, got %d", inbox.Count)
+	}
+
+	// Reading marks it read
+	var read ReadOutput
+	apiCall(t, srv, "GET", "/api/messages/"+sent.ShortID, "dev-token", nil, &read)
+	if read.Body != "Please build it" || read.Status != "read" {
+		t.Errorf("unexpected read output: %+v", read)
+	}
+	apiCall(t, srv, "GET", "/api/inbox", "dev-token", nil, &inbox)
+	if inbox.Count != 0 {
+		t.Errorf("expected no unread for dev after read, got %d", inbox.Count)
+	}
+
+	// dev replies; pm sees it in the thread
+	var reply ReplyOutput
+	code = apiCall(t, srv, "POST", "/api/messages/"+sent.ID+"/reply", "dev-token",
+		ReplyRequest{Body: "Done"}, &reply)
+	if code != http.StatusCreated || reply.ThreadID != sent.ID {
+		t.Fatalf("unexpected reply: %d %+v", code, reply)
+	}
+
+	var thread ThreadOutput
+	apiCall(t, srv, "GET", "/api/messages/"+sent.ID+"/thread", "pm-token", nil, &thread)
+	if thread.Count != 2 {
+		t.Errorf("expected 2 messages in thread, got %d", thread.Count)
+	}
+
+	// Threads the caller has no part in are not found
+	database.SendMessage(&db.Message{
+		ID: "msg001", FromID: "pm", Subject: "Private", Body: "For qa only",
+		Priority: "normal", MsgType: "message", CreatedAt: time.Now(),
+	}, []string{"qa"})
+	if code := apiCall(t, srv, "GET", "/api/messages/msg001/thread", "dev-token", nil, nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a thread dev is not in, got %d", code)
+	}
+
+	// Archive and delete act on the caller's copy only
+	var action MessageActionOutput
+	apiCall(t, srv, "POST", "/api/messages/"+reply.ID+"/archive", "pm-token", nil, &action)
+	if action.Status != "archived" {
+		t.Errorf("expected archived, got %+v", action)
+	}
+	var archived InboxOutput
+	apiCall(t, srv, "GET", "/api/inbox?archived=true", "pm-token", nil, &archived)
+	if archived.Count != 1 || archived.Messages[0].ID != reply.ID {
+		t.Errorf("expected the reply in pm's archive, got %+v", archived)
+	}
+	apiCall(t, srv, "POST", "/api/messages/"+reply.ID+"/unarchive", "pm-token", nil, &action)
+	if action.Status != "read" {
+		t.Errorf("expected read after unarchive, got %+v", action)
+	}
+	if code := apiCall(t, srv, "POST", "/api/messages/"+reply.ID+"/unarchive", "pm-token", nil, nil); code != http.StatusConflict {
+		t.Errorf("expected 409 unarchiving a message that isn't archived, got %d", code)
+	}
+	if code := apiCall(t, srv, "DELETE", "/api/messages/"+reply.ID, "dev-token", nil, nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 deleting a message not in dev's inbox, got %d", code)
+	}
+}
+
+func TestServeValidation(t *testing.T) {
+	srv, _ := setupTestServer(t)
+
+	code := apiCall(t, srv, "POST", "/api/messages", "pm-token",
+		SendRequest{To: "nobody", Subject: "x", Body: "x"}, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown recipient, got %d", code)
+	}
+
+	code = apiCall(t, srv, "POST", "/api/messages", "pm-token",
+		SendRequest{To: "dev", Subject: "x", Body: "x", Priority: "whenever"}, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid priority, got %d", code)
+	}
+}
+
+func TestServeStream(t *testing.T) {
+	srv, database := setupTestServer(t)
+
+	send := func(id, to string) {
+		database.SendMessage(&db.Message{
+			ID: id, FromID: "pm", Subject: "Hi " + to, Body: "Body",
+			Priority: "normal", MsgType: "message", CreatedAt: time.Now(),
+		}, []string{to})
+	}
+
+	// Mail to someone else, and mail dev's inbox hides, is not streamed
+	send("msg001", "qa")
+	send("msg002", "dev")
+	database.RecallMessage("msg002", "pm")
+	send("msg003", "dev")
+	database.Archive("msg003", "dev")
+
+	// Resume from the start of the event log
+	req, _ := http.NewRequest("GET", srv.URL+"/api/inbox/stream?access_token=dev-token", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream, got %q", ct)
+	}
+
+	// New mail to dev is
+	send("msg004", "dev")
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("stream closed before message event")
+			}
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var msg InboxMessageJSON
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+				t.Fatalf("invalid event data: %v", err)
+			}
+			if msg.ID != "msg004" || msg.Status != "unread" {
+				t.Errorf("expected only dev's unread msg004, got %s (%s)", msg.ID, msg.Status)
+			}
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for message event")
+		}
+	}
+}
+
+func TestServeStats(t *testing.T) {
+	srv, database := setupTestServer(t)
+
+	for _, to := range []string{"dev", "qa"} {
+		database.SendMessage(&db.Message{
+			ID: "msg-" + to, FromID: "pm", Subject: "Hi", Body: "Body",
+			Priority: "normal", MsgType: "message", CreatedAt: time.Now(),
+		}, []string{to})
+	}
+
+	// Only the token's own role is counted
+	var stats StatsOutput
+	apiCall(t, srv, "GET", "/api/stats", "dev-token", nil, &stats)
+	if len(stats.Roles) != 1 || stats.Roles[0].Role != "dev" || stats.TotalUnread != 1 {
+		t.Errorf("expected dev's stats only, got %+v", stats)
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// activeSessionWindow is how recently a session must have run amail to be
+// listed as active
+const activeSessionWindow = time.Hour
+
+// SessionsOutput is the JSON output structure for the sessions command
+type SessionsOutput struct {
+	Sessions []SessionJSON `json:"sessions"`
+	Count    int           `json:"count"`
+}
+
+// SessionJSON is the JSON representation of a role instance
+type SessionJSON struct {
+	ID        string `json:"id"`
+	Role      string `json:"role"`
+	StartedAt string `json:"started_at"`
+	LastSeen  string `json:"last_seen"`
+}
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List active instances of each role",
+	Long: `List the named instances of each role (dev#1, dev#2) that have used
+amail in the last hour. Each instance receives its role's mail but keeps
+its own read and notified state.
+
+Start an instance with:
+  source <(amail use dev#1)
+
+Examples:
+  amail sessions
+  amail sessions --all`,
+	Args: cobra.NoArgs,
+	RunE: runSessions,
+}
+
+var sessionsAll bool
+
+func init() {
+	sessionsCmd.Flags().BoolVarP(&sessionsAll, "all", "a", false, "Include sessions not seen recently")
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+func runSessions(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, _, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var since time.Time
+	if !sessionsAll {
+		since = time.Now().Add(-activeSessionWindow)
+	}
+	sessions, err := database.GetSessions(since)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := SessionsOutput{
+			Sessions: make([]SessionJSON, len(sessions)),
+			Count:    len(sessions),
+		}
+		for i, s := range sessions {
+			output.Sessions[i] = SessionJSON{
+				ID:        s.ID,
+				Role:      s.Role,
+				StartedAt: s.StartedAt.Format(time.RFC3339),
+				LastSeen:  s.LastSeen.Format(time.RFC3339),
+			}
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(sessions) == 0 {
+		fmt.Println("No active sessions.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROLE\tSESSION\tSTARTED\tLAST SEEN")
+	fmt.Fprintln(w, "----\t-------\t-------\t---------")
+	for _, s := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Role, s.ID, formatTimeAgo(s.StartedAt), formatTimeAgo(s.LastSeen))
+	}
+	w.Flush()
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// SnoozeOutput is the JSON output structure for snoozing a message
+type SnoozeOutput struct {
+	ID           string `json:"id"`
+	ShortID      string `json:"short_id"`
+	SnoozedUntil string `json:"snoozed_until"`
+}
+
+// UnsnoozeOutput is the JSON output structure for clearing a snooze
+type UnsnoozeOutput struct {
+	ID      string `json:"id"`
+	ShortID string `json:"short_id"`
+	Status  string `json:"status"`
+}
+
+// SnoozedOutput is the JSON output structure for listing snoozed messages
+type SnoozedOutput struct {
+	Messages []SnoozedMessageJSON `json:"messages"`
+	Count    int                  `json:"count"`
+}
+
+// SnoozedMessageJSON is the JSON representation of a snoozed message
+type SnoozedMessageJSON struct {
+	ID           string `json:"id"`
+	ShortID      string `json:"short_id"`
+	From         string `json:"from"`
+	Subject      string `json:"subject"`
+	Priority     string `json:"priority"`
+	Type         string `json:"type"`
+	CreatedAt    string `json:"created_at"`
+	SnoozedUntil string `json:"snoozed_until"`
+}
+
+var snoozeClear bool
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze [message-id] [duration|time]",
+	Short: "Hide a message until later",
+	Long: `Hide a message from inbox and count until the snooze expires. It then
+comes back as unread and is notified again by watch.
+
+The second argument is a delay from now (30m, 2h, 1d), a time of day
+(18:00) or a date/time (YYYY-MM-DDTHH:MM or RFC3339). With no arguments, lists your snoozed
+messages. Only unread or read mail in your inbox can be snoozed.
+
+Use --clear to bring a snoozed message back now, as unread.
+
+Examples:
+  amail snooze abc123 1h
+  amail snooze abc123 "2026-10-17T09:00"
+  amail snooze --clear abc123
+  amail snooze`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if snoozeClear {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		if len(args) == 1 {
+			return fmt.Errorf("snooze requires a duration or time after the message ID")
+		}
+		return cobra.MaximumNArgs(2)(cmd, args)
+	},
+	RunE: runSnooze,
+}
+
+func init() {
+	snoozeCmd.Flags().BoolVar(&snoozeClear, "clear", false, "Cancel the snooze on a message")
+	rootCmd.AddCommand(snoozeCmd)
+}
+
+func runSnooze(cmd *cobra.Command, args []string) error {
+	var until time.Time
+	if len(args) == 2 {
+		var err error
+		if until, err = parseUntil(args[1], time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
+	toID := res.Identity
+
+	if len(args) == 0 {
+		return listSnoozed(database, toID)
+	}
+	if snoozeClear {
+		return clearSnooze(database, args[0], toID)
+	}
+
+	msg, err := findMessageByPrefix(database, args[0], toID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message not found: %s", args[0])
+	}
+
+	snoozed, err := database.Snooze(msg.ID, toID, until)
+	if err != nil {
+		return err
+	}
+	if !snoozed {
+		return fmt.Errorf("message %s is %s; only unread or read mail can be snoozed", SafeShortID(msg.ID), msg.Status)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(SnoozeOutput{
+			ID:           msg.ID,
+			ShortID:      SafeShortID(msg.ID),
+			SnoozedUntil: until.Format(time.RFC3339),
+		})
+	}
+
+	// Text output
+	fmt.Printf("✓ Snoozed %s until %s\n", SafeShortID(msg.ID), until.Format("2006-01-02 15:04"))
+	return nil
+}
+
+// clearSnooze brings one of toID's snoozed messages back to the inbox
+func clearSnooze(database *db.DB, prefix, toID string) error {
+	snoozed, err := database.GetSnoozed(toID)
+	if err != nil {
+		return fmt.Errorf("failed to get snoozed messages: %w", err)
+	}
+
+	// Find by prefix
+	var matches []*db.SnoozedMessage
+	for i := range snoozed {
+		if strings.HasPrefix(snoozed[i].ID, prefix) {
+			matches = append(matches, &snoozed[i])
+		}
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no snoozed message: %s", prefix)
+	}
+	if len(matches) > 1 {
+		return fmt.Errorf("ambiguous ID prefix: %s matches %d messages", prefix, len(matches))
+	}
+	msg := matches[0]
+
+	cleared, err := database.Unsnooze(msg.ID, toID)
+	if err != nil {
+		return err
+	}
+	if !cleared {
+		return fmt.Errorf("no snoozed message: %s", prefix)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(UnsnoozeOutput{
+			ID:      msg.ID,
+			ShortID: SafeShortID(msg.ID),
+			Status:  db.StatusUnread,
+		})
+	}
+
+	// Text output
+	fmt.Printf("✓ Unsnoozed %s\n", SafeShortID(msg.ID))
+	return nil
+}
+
+// listSnoozed prints toID's snoozed messages
+func listSnoozed(database *db.DB, toID string) error {
+	snoozed, err := database.GetSnoozed(toID)
+	if err != nil {
+		return fmt.Errorf("failed to get snoozed messages: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := SnoozedOutput{
+			Messages: make([]SnoozedMessageJSON, len(snoozed)),
+			Count:    len(snoozed),
+		}
+		for i, m := range snoozed {
+			output.Messages[i] = SnoozedMessageJSON{
+				ID:           m.ID,
+				ShortID:      SafeShortID(m.ID),
+				From:         m.FromID,
+				Subject:      m.Subject,
+				Priority:     m.Priority,
+				Type:         m.MsgType,
+				CreatedAt:    m.CreatedAt.Format(time.RFC3339),
+				SnoozedUntil: m.SnoozedUntil.Local().Format(time.RFC3339),
+			}
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(snoozed) == 0 {
+		fmt.Println("No snoozed messages.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFROM\tSUBJECT\tUNTIL")
+	fmt.Fprintln(w, "--\t----\t-------\t-----")
+	for _, m := range snoozed {
+		subject := m.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			SafeShortID(m.ID), m.FromID, truncate(subject, 30),
+			m.SnoozedUntil.Local().Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+
+	return nil
+}
@@ -3,6 +3,8 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -19,9 +21,10 @@ type StatsOutput struct {
 
 // RoleStatsJSON is the JSON representation of role statistics
 type RoleStatsJSON struct {
-	Role   string `json:"role"`
-	Unread int    `json:"unread"`
-	Total  int    `json:"total"`
+	Role   string         `json:"role"`
+	Unread int            `json:"unread"`
+	Total  int            `json:"total"`
+	Labels map[string]int `json:"labels,omitempty"`
 }
 
 var statsCmd = &cobra.Command{
@@ -52,40 +55,10 @@ func runStats(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Collect stats for each role
-	allRoles := cfg.AllRoles()
-	var roleStats []RoleStatsJSON
-	var totalUnread, totalAll int
-
-	for _, role := range allRoles {
-		unread, err := database.CountUnread(role)
-		if err != nil {
-			continue
-		}
-
-		all, err := countAll(database, role)
-		if err != nil {
-			continue
-		}
-
-		if all > 0 {
-			roleStats = append(roleStats, RoleStatsJSON{
-				Role:   role,
-				Unread: unread,
-				Total:  all,
-			})
-			totalUnread += unread
-			totalAll += all
-		}
-	}
+	output := collectStats(database, cfg.AllRoles())
 
 	// JSON output
 	if IsJSONOutput() {
-		output := StatsOutput{
-			Roles:       roleStats,
-			TotalUnread: totalUnread,
-			TotalAll:    totalAll,
-		}
 		return PrintJSON(output)
 	}
 
@@ -95,24 +68,82 @@ func runStats(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ROLE\tUNREAD\tTOTAL")
-	fmt.Fprintln(w, "----\t------\t-----")
+	fmt.Fprintln(w, "ROLE\tUNREAD\tTOTAL\tLABELS")
+	fmt.Fprintln(w, "----\t------\t-----\t------")
 
-	for _, rs := range roleStats {
-		fmt.Fprintf(w, "%s\t%d\t%d\n", rs.Role, rs.Unread, rs.Total)
+	for _, rs := range output.Roles {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", rs.Role, rs.Unread, rs.Total, formatLabelCounts(rs.Labels))
 	}
 
-	fmt.Fprintln(w, "----\t------\t-----")
-	fmt.Fprintf(w, "TOTAL\t%d\t%d\n", totalUnread, totalAll)
+	fmt.Fprintln(w, "----\t------\t-----\t------")
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t\n", output.TotalUnread, output.TotalAll)
 	w.Flush()
 
 	return nil
 }
 
+// collectStats counts unread, total and labelled messages for each of roles
+// with mail
+func collectStats(database *db.DB, roles []string) StatsOutput {
+	var output StatsOutput
+
+	for _, role := range roles {
+		unread, err := database.CountUnread(role)
+		if err != nil {
+			continue
+		}
+
+		all, err := countAll(database, role)
+		if err != nil {
+			continue
+		}
+
+		if all > 0 {
+			rs := RoleStatsJSON{
+				Role:   role,
+				Unread: unread,
+				Total:  all,
+			}
+			if counts, err := database.CountLabels(role); err == nil && len(counts) > 0 {
+				rs.Labels = make(map[string]int, len(counts))
+				for _, c := range counts {
+					rs.Labels[c.Label] = c.Count
+				}
+			}
+			output.Roles = append(output.Roles, rs)
+			output.TotalUnread += unread
+			output.TotalAll += all
+		}
+	}
+
+	return output
+}
+
 func countAll(database *db.DB, toID string) (int, error) {
-	messages, err := database.GetInbox(toID, true)
+	messages, err := database.GetInbox(toID, db.StatusUnread, db.StatusRead, db.StatusArchived)
 	if err != nil {
 		return 0, err
 	}
 	return len(messages), nil
 }
+
+// formatLabelCounts renders label counts as "#blocked:2 #todo:1", most used
+// first
+func formatLabelCounts(counts map[string]int) string {
+	labels := make([]string, 0, len(counts))
+	for l := range counts {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if counts[labels[i]] != counts[labels[j]] {
+			return counts[labels[i]] > counts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = fmt.Sprintf("#%s:%d", l, counts[l])
+	}
+	return strings.Join(parts, " ")
+}
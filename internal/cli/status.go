@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// StatusOutput is the JSON output structure for the status command
+type StatusOutput struct {
+	ID         string                `json:"id"`
+	ShortID    string                `json:"short_id"`
+	From       string                `json:"from"`
+	Subject    string                `json:"subject"`
+	CreatedAt  string                `json:"created_at"`
+	EditedAt   *string               `json:"edited_at,omitempty"`
+	RecalledAt *string               `json:"recalled_at,omitempty"`
+	Recipients []RecipientStatusJSON `json:"recipients"`
+	Summary    StatusSummaryJSON     `json:"summary"`
+}
+
+// ThreadStatusOutput is the JSON output structure for status --thread
+type ThreadStatusOutput struct {
+	ThreadID string            `json:"thread_id"`
+	Subject  string            `json:"subject"`
+	Messages []StatusOutput    `json:"messages"`
+	Summary  StatusSummaryJSON `json:"summary"`
+	Count    int               `json:"count"`
+}
+
+// StatusSummaryJSON counts recipients in each delivery state
+type StatusSummaryJSON struct {
+	Delivered int `json:"delivered"`
+	Notified  int `json:"notified"`
+	Read      int `json:"read"`
+	Archived  int `json:"archived"`
+	Trashed   int `json:"trashed"`
+	Recalled  int `json:"recalled"`
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status <message-id>",
+	Short: "Show per-recipient delivery and read status",
+	Long: `Show, for each recipient of a message, whether it was delivered,
+notified, read, archived or trashed, and when.
+
+Messages are delivered to every recipient as soon as they are sent.
+Use --thread to see a rollup for every message in the thread.
+
+Examples:
+  amail status abc123
+  amail status abc123 --thread`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStatus,
+}
+
+var statusThread bool
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusThread, "thread", false, "Show a rollup for the whole thread")
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, _, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	msg, err := findAnyMessage(database, args[0])
+	if err != nil {
+		return err
+	}
+
+	if statusThread {
+		return printThreadStatus(database, threadRoot(msg))
+	}
+
+	recipients, err := database.GetRecipientStatus(msg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient status: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(toStatusOutput(msg, recipients))
+	}
+
+	// Text output
+	subject := msg.Subject
+	if subject == "" {
+		subject = "(no subject)"
+	}
+	fmt.Printf("[%s] %s from %s\n", SafeShortID(msg.ID), subject, msg.FromID)
+	fmt.Printf("Delivered: %s (%s)\n", formatStatusTime(&msg.CreatedAt), formatTimeAgo(msg.CreatedAt))
+	if msg.EditedAt != nil {
+		fmt.Printf("Edited:    %s (%s)\n", formatStatusTime(msg.EditedAt), formatTimeAgo(*msg.EditedAt))
+	}
+	if msg.RecalledAt != nil {
+		fmt.Printf("Recalled:  %s (%s)\n", formatStatusTime(msg.RecalledAt), formatTimeAgo(*msg.RecalledAt))
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RECIPIENT\tSTATUS\tNOTIFIED\tREAD\tARCHIVED\tTRASHED")
+	fmt.Fprintln(w, "---------\t------\t--------\t----\t--------\t-------")
+	for _, r := range recipients {
+		status := r.Status
+		switch {
+		case r.AckedAt != nil:
+			status += " (done by " + r.ClaimedBy + ")"
+		case r.ClaimedBy != "":
+			status += " (claimed by " + r.ClaimedBy + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.ToID, status,
+			formatStatusTime(r.NotifiedAt), formatStatusTime(r.ReadAt),
+			formatStatusTime(r.ArchivedAt), formatStatusTime(r.TrashedAt))
+	}
+	w.Flush()
+
+	return nil
+}
+
+// printThreadStatus prints a per-message rollup of recipient status for a thread
+func printThreadStatus(database *db.DB, threadID string) error {
+	messages, err := database.GetThread(threadID, "")
+	if err != nil {
+		return fmt.Errorf("failed to get thread: %w", err)
+	}
+
+	statuses, err := database.GetThreadRecipientStatus(threadID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient status: %w", err)
+	}
+
+	subject := ""
+	if len(messages) > 0 {
+		subject = messages[0].Subject
+	}
+	if subject == "" {
+		subject = "(no subject)"
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := ThreadStatusOutput{
+			ThreadID: threadID,
+			Subject:  subject,
+			Messages: make([]StatusOutput, len(messages)),
+			Count:    len(messages),
+		}
+		for i := range messages {
+			output.Messages[i] = toStatusOutput(&messages[i], statuses[messages[i].ID])
+			output.Summary.add(output.Messages[i].Summary)
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(messages) == 0 {
+		fmt.Println("No messages in thread.")
+		return nil
+	}
+
+	fmt.Printf("Thread: %s (%d messages)\n", subject, len(messages))
+	fmt.Println()
+
+	var total StatusSummaryJSON
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFROM\tTO\tNOTIFIED\tREAD\tARCHIVED\tTRASHED\tTIME")
+	fmt.Fprintln(w, "--\t----\t--\t--------\t----\t--------\t-------\t----")
+	for _, m := range messages {
+		recipients := statuses[m.ID]
+		s := summarizeRecipients(recipients)
+		total.add(s)
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%d/%d\t%d/%d\t%d/%d\t%s\n",
+			SafeShortID(m.ID), m.FromID, formatRecipientStatus(recipients),
+			s.Notified, s.Delivered, s.Read, s.Delivered,
+			s.Archived, s.Delivered, s.Trashed, s.Delivered,
+			formatTimeAgo(m.CreatedAt))
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Printf("Total: %d delivered, %d notified, %d read, %d archived, %d trashed, %d recalled\n",
+		total.Delivered, total.Notified, total.Read, total.Archived, total.Trashed, total.Recalled)
+
+	return nil
+}
+
+// toStatusOutput builds the JSON status of a message and its recipients
+func toStatusOutput(msg *db.InboxMessage, recipients []db.Recipient) StatusOutput {
+	return StatusOutput{
+		ID:         msg.ID,
+		ShortID:    SafeShortID(msg.ID),
+		From:       msg.FromID,
+		Subject:    msg.Subject,
+		CreatedAt:  msg.CreatedAt.Format(time.RFC3339),
+		EditedAt:   formatOptionalTime(msg.EditedAt),
+		RecalledAt: formatOptionalTime(msg.RecalledAt),
+		Recipients: toRecipientStatusJSON(recipients),
+		Summary:    summarizeRecipients(recipients),
+	}
+}
+
+// summarizeRecipients counts recipients in each delivery state. Archived and
+// trashed messages still count as read if they were read first.
+func summarizeRecipients(recipients []db.Recipient) StatusSummaryJSON {
+	var s StatusSummaryJSON
+	for _, r := range recipients {
+		s.Delivered++
+		if r.NotifiedAt != nil {
+			s.Notified++
+		}
+		if r.ReadAt != nil {
+			s.Read++
+		}
+		switch r.Status {
+		case db.StatusArchived:
+			s.Archived++
+		case db.StatusTrashed:
+			s.Trashed++
+		case db.StatusRecalled:
+			s.Recalled++
+		}
+	}
+	return s
+}
+
+// add accumulates another summary into s
+func (s *StatusSummaryJSON) add(other StatusSummaryJSON) {
+	s.Delivered += other.Delivered
+	s.Notified += other.Notified
+	s.Read += other.Read
+	s.Archived += other.Archived
+	s.Trashed += other.Trashed
+	s.Recalled += other.Recalled
+}
+
+// formatStatusTime formats an optional timestamp for the status table
+func formatStatusTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("Jan 2 15:04:05")
+}
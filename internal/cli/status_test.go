@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thirteen37/amail/internal/db"
+)
+
+func TestSummarizeRecipients(t *testing.T) {
+	now := time.Now()
+	recipients := []db.Recipient{
+		{ToID: "dev", Status: "read", NotifiedAt: &now, ReadAt: &now},
+		{ToID: "qa", Status: "archived", ReadAt: &now, ArchivedAt: &now},
+		{ToID: "research", Status: "trashed", TrashedAt: &now},
+		{ToID: "pm", Status: "unread"},
+		{ToID: "ops", Status: "recalled"},
+	}
+
+	got := summarizeRecipients(recipients)
+	want := StatusSummaryJSON{Delivered: 5, Notified: 1, Read: 2, Archived: 1, Trashed: 1, Recalled: 1}
+	if got != want {
+		t.Errorf("summarizeRecipients() = %+v, want %+v", got, want)
+	}
+
+	got.add(want)
+	if got.Delivered != 10 || got.Read != 4 {
+		t.Errorf("add() = %+v", got)
+	}
+}
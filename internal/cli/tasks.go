@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// TasksOutput is the JSON output structure for the tasks command
+type TasksOutput struct {
+	Tasks []TaskJSON `json:"tasks"`
+	Count int        `json:"count"`
+}
+
+// TaskJSON is the JSON representation of a request assigned to you
+type TaskJSON struct {
+	ID        string   `json:"id"`
+	ShortID   string   `json:"short_id"`
+	From      string   `json:"from"`
+	To        []string `json:"to"`
+	Subject   string   `json:"subject"`
+	Priority  string   `json:"priority"`
+	State     string   `json:"state"`
+	DueAt     *string  `json:"due_at,omitempty"`
+	Overdue   bool     `json:"overdue,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// TaskStateOutput is the JSON output structure for changing a task's state
+type TaskStateOutput struct {
+	ID      string `json:"id"`
+	ShortID string `json:"short_id"`
+	State   string `json:"state"`
+}
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "List requests assigned to you",
+	Long: `List requests sent to you that you have not finished or declined,
+oldest first.
+
+Each request moves through open, acknowledged, in-progress and then done
+or declined. Replying with type response (the default for reply) marks it
+done.
+
+Examples:
+  amail tasks
+  amail tasks --all
+  amail tasks ack abc123
+  amail tasks start abc123
+  amail tasks done abc123
+  amail tasks decline abc123`,
+	Args: cobra.NoArgs,
+	RunE: runTasks,
+}
+
+var taskAckCmd = &cobra.Command{
+	Use:   "ack <message-id>",
+	Short: "Acknowledge a request",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskState,
+}
+
+var taskStartCmd = &cobra.Command{
+	Use:   "start <message-id>",
+	Short: "Mark a request in progress",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskState,
+}
+
+var taskDoneCmd = &cobra.Command{
+	Use:   "done <message-id>",
+	Short: "Mark a request done without replying",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskState,
+}
+
+var taskDeclineCmd = &cobra.Command{
+	Use:   "decline <message-id>",
+	Short: "Decline a request",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskState,
+}
+
+var taskReopenCmd = &cobra.Command{
+	Use:   "reopen <message-id>",
+	Short: "Move a request back to open",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTaskState,
+}
+
+// taskStates maps each tasks subcommand to the state it moves a request to
+var taskStates = map[string]string{
+	"ack":     db.RequestAcknowledged,
+	"start":   db.RequestInProgress,
+	"done":    db.RequestDone,
+	"decline": db.RequestDeclined,
+	"reopen":  db.RequestOpen,
+}
+
+var tasksAll bool
+
+func init() {
+	tasksCmd.Flags().BoolVarP(&tasksAll, "all", "a", false, "Include done and declined requests")
+	tasksCmd.AddCommand(taskAckCmd)
+	tasksCmd.AddCommand(taskStartCmd)
+	tasksCmd.AddCommand(taskDoneCmd)
+	tasksCmd.AddCommand(taskDeclineCmd)
+	tasksCmd.AddCommand(taskReopenCmd)
+	rootCmd.AddCommand(tasksCmd)
+}
+
+func runTasks(cmd *cobra.Command, args []string) error {
+	database, toID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	states := append([]string{}, db.OpenRequestStates...)
+	if tasksAll {
+		states = append(states, db.RequestDone, db.RequestDeclined)
+	}
+	tasks, err := database.GetTasks(toID, states...)
+	if err != nil {
+		return fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := TasksOutput{
+			Tasks: make([]TaskJSON, len(tasks)),
+			Count: len(tasks),
+		}
+		now := time.Now()
+		for i, t := range tasks {
+			output.Tasks[i] = TaskJSON{
+				ID:        t.ID,
+				ShortID:   SafeShortID(t.ID),
+				From:      t.FromID,
+				To:        t.ToIDs,
+				Subject:   t.Subject,
+				Priority:  t.Priority,
+				State:     t.RequestState,
+				DueAt:     formatOptionalTime(t.DueAt),
+				Overdue:   t.Overdue(now),
+				CreatedAt: t.CreatedAt.Format(time.RFC3339),
+			}
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(tasks) == 0 {
+		fmt.Println("No open requests.")
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFROM\tSUBJECT\tSTATE\tPRIORITY\tDUE\tAGE")
+	fmt.Fprintln(w, "--\t----\t-------\t-----\t--------\t---\t---")
+	for i := range tasks {
+		t := &tasks[i]
+		subject := t.Subject
+		if subject == "" {
+			subject = "(no subject)"
+		}
+		unread := ""
+		if t.Status == db.StatusUnread {
+			unread = "*"
+		}
+		due := formatDue(t, now)
+		if due == "" {
+			due = "-"
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			unread, SafeShortID(t.ID), t.FromID, truncate(subject, 30), t.RequestState, t.Priority,
+			due, formatTimeAgo(t.CreatedAt))
+	}
+	w.Flush()
+
+	return nil
+}
+
+// runTaskState moves one of the caller's requests to the state named by
+// the subcommand
+func runTaskState(cmd *cobra.Command, args []string) error {
+	prefix := args[0]
+	state := taskStates[cmd.Name()]
+
+	database, toID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	tasks, err := database.GetTasks(toID, db.RequestOpen, db.RequestAcknowledged,
+		db.RequestInProgress, db.RequestDone, db.RequestDeclined)
+	if err != nil {
+		return fmt.Errorf("failed to get tasks: %w", err)
+	}
+	var matches []*db.InboxMessage
+	for i := range tasks {
+		if strings.HasPrefix(tasks[i].ID, prefix) {
+			matches = append(matches, &tasks[i])
+		}
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no request for you: %s", prefix)
+	}
+	if len(matches) > 1 {
+		return fmt.Errorf("ambiguous ID prefix: %s matches %d requests", prefix, len(matches))
+	}
+	task := matches[0]
+
+	if _, err := database.SetRequestState(task.ID, toID, state); err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(TaskStateOutput{ID: task.ID, ShortID: SafeShortID(task.ID), State: state})
+	}
+
+	// Text output
+	fmt.Printf("✓ %s: %s → %s\n", SafeShortID(task.ID), task.RequestState, state)
+	return nil
+}
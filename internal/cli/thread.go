@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
 	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
 )
 
 // ThreadOutput is the JSON output structure for the thread command
@@ -21,12 +23,15 @@ type ThreadOutput struct {
 
 // ThreadMessageJSON is the JSON representation of a thread message
 type ThreadMessageJSON struct {
-	ID        string   `json:"id"`
-	ShortID   string   `json:"short_id"`
-	From      string   `json:"from"`
-	To        []string `json:"to"`
-	Body      string   `json:"body"`
-	CreatedAt string   `json:"created_at"`
+	ID          string           `json:"id"`
+	ShortID     string           `json:"short_id"`
+	From        string           `json:"from"`
+	To          []string         `json:"to"`
+	Body        string           `json:"body"`
+	CreatedAt   string           `json:"created_at"`
+	Attachments []AttachmentJSON `json:"attachments,omitempty"`
+	Edited      bool             `json:"edited,omitempty"`
+	Recalled    bool             `json:"recalled,omitempty"`
 }
 
 var threadCmd = &cobra.Command{
@@ -51,70 +56,48 @@ func runThread(cmd *cobra.Command, args []string) error {
 	messageIDArg := args[0]
 
 	// Open project
-	database, _, err := db.OpenProject()
+	database, root, err := db.OpenProject()
 	if err != nil {
 		return err
 	}
 	defer database.Close()
 
-	// Find the message to get thread ID (try exact match first, then prefix)
-	msg, err := database.GetMessage(messageIDArg)
+	// Load config
+	cfg, err := config.LoadProject(root)
 	if err != nil {
-		return fmt.Errorf("failed to get message: %w", err)
+		return fmt.Errorf("failed to load config: %w", err)
 	}
-	if msg == nil {
-		// Try prefix match
-		msg, err = database.FindMessageByPrefix(messageIDArg)
-		if err != nil {
-			return fmt.Errorf("failed to find message: %w", err)
-		}
+
+	// Resolve identity; without one, recalled messages stay hidden
+	viewer := ""
+	res, err := identity.Resolve(cfg)
+	if err != nil {
+		return err
 	}
-	if msg == nil {
-		return fmt.Errorf("message not found: %s", messageIDArg)
+	if res != nil {
+		if err := useSession(database, res); err != nil {
+			return err
+		}
+		viewer = res.Identity
 	}
 
-	// Determine thread root
-	var threadRootID string
-	if msg.ThreadID != nil {
-		threadRootID = *msg.ThreadID
-	} else {
-		// This message might be the root
-		threadRootID = msg.ID
+	// Find the message to get thread ID
+	msg, err := findAnyMessage(database, messageIDArg)
+	if err != nil {
+		return err
 	}
+	threadRootID := threadRoot(msg)
 
 	// Get all messages in thread
-	messages, err := database.GetThread(threadRootID)
+	messages, err := database.GetThread(threadRootID, viewer)
 	if err != nil {
 		return fmt.Errorf("failed to get thread: %w", err)
 	}
 
-	// Get subject from first message
-	subject := ""
-	if len(messages) > 0 {
-		subject = messages[0].Subject
-	}
-	if subject == "" {
-		subject = "(no subject)"
-	}
+	output := toThreadOutput(threadRootID, messages)
 
 	// JSON output
 	if IsJSONOutput() {
-		output := ThreadOutput{
-			ThreadID: threadRootID,
-			Subject:  subject,
-			Messages: make([]ThreadMessageJSON, len(messages)),
-			Count:    len(messages),
-		}
-		for i, m := range messages {
-			output.Messages[i] = ThreadMessageJSON{
-				ID:        m.ID,
-				ShortID:   SafeShortID(m.ID),
-				From:      m.FromID,
-				To:        m.ToIDs,
-				Body:      m.Body,
-				CreatedAt: m.CreatedAt.Format(time.RFC3339),
-			}
-		}
 		return PrintJSON(output)
 	}
 
@@ -124,7 +107,7 @@ func runThread(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("Thread: %s (%d messages)\n", subject, len(messages))
+	fmt.Printf("Thread: %s (%d messages)\n", output.Subject, len(messages))
 	fmt.Println()
 
 	// Print table
@@ -153,11 +136,78 @@ func runThread(cmd *cobra.Command, args []string) error {
 		if i > 0 {
 			fmt.Println(strings.Repeat("-", 40))
 		}
-		fmt.Printf("[%s] %s → %s (%s)\n", SafeShortID(m.ID), m.FromID, strings.Join(m.ToIDs, ","), m.CreatedAt.Format("15:04"))
+		fmt.Printf("[%s] %s → %s (%s)%s\n", SafeShortID(m.ID), m.FromID, strings.Join(m.ToIDs, ","), m.CreatedAt.Format("15:04"), revisionTag(&m.Message))
 		fmt.Println()
-		fmt.Println(m.Body)
+		if m.RecalledAt != nil && m.Body == "" {
+			fmt.Println("(recalled by the sender)")
+		} else {
+			fmt.Println(m.Body)
+		}
 		fmt.Println()
+		printAttachments(m.Attachments)
 	}
 
 	return nil
 }
+
+// toThreadOutput converts the messages of a thread to JSON output
+func toThreadOutput(threadID string, messages []db.InboxMessage) ThreadOutput {
+	// Get subject from first message
+	subject := ""
+	if len(messages) > 0 {
+		subject = messages[0].Subject
+	}
+	if subject == "" {
+		subject = "(no subject)"
+	}
+
+	output := ThreadOutput{
+		ThreadID: threadID,
+		Subject:  subject,
+		Messages: make([]ThreadMessageJSON, len(messages)),
+		Count:    len(messages),
+	}
+	for i, m := range messages {
+		output.Messages[i] = ThreadMessageJSON{
+			ID:          m.ID,
+			ShortID:     SafeShortID(m.ID),
+			From:        m.FromID,
+			To:          m.ToIDs,
+			Body:        m.Body,
+			CreatedAt:   m.CreatedAt.Format(time.RFC3339),
+			Attachments: toAttachmentsJSON(m.Attachments),
+			Edited:      m.EditedAt != nil,
+			Recalled:    m.RecalledAt != nil,
+		}
+	}
+	return output
+}
+
+// findAnyMessage looks up a message by ID regardless of recipient, trying an
+// exact match first and then a prefix match
+func findAnyMessage(database *db.DB, idArg string) (*db.InboxMessage, error) {
+	msg, err := database.GetMessage(idArg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	if msg == nil {
+		// Try prefix match
+		msg, err = database.FindMessageByPrefix(idArg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find message: %w", err)
+		}
+	}
+	if msg == nil {
+		return nil, fmt.Errorf("message not found: %s", idArg)
+	}
+	return msg, nil
+}
+
+// threadRoot returns the ID of the thread a message belongs to
+func threadRoot(msg *db.InboxMessage) string {
+	if msg.ThreadID != nil {
+		return *msg.ThreadID
+	}
+	// This message might be the root
+	return msg.ID
+}
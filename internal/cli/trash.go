@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+// TrashEmptyOutput is the JSON output structure for the trash empty command
+type TrashEmptyOutput struct {
+	Purged int `json:"purged"`
+}
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List or empty deleted messages",
+	Long: `Messages you delete are moved to the trash. They can be restored with
+'amail undelete' until the trash is emptied, which removes them for good.
+
+Examples:
+  amail trash list
+  amail trash empty --older-than 30d
+  amail trash empty`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List messages in the trash",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently remove messages from the trash",
+	Long: `Permanently remove messages from your trash. Other recipients and the
+sender keep their copies, so attachments stay in .amail/blobs/ while the
+message exists; 'amail attachment gc' removes blobs no message references.
+
+Examples:
+  amail trash empty                  # Everything in the trash
+  amail trash empty --older-than 30d # Only messages trashed 30+ days ago`,
+	Args: cobra.NoArgs,
+	RunE: runTrashEmpty,
+}
+
+var undeleteCmd = &cobra.Command{
+	Use:   "undelete <message-id>",
+	Short: "Restore a message from the trash",
+	Long: `Move a message from the trash back to your inbox, marked as read.
+
+Examples:
+  amail trash list
+  amail undelete abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUndelete,
+}
+
+var trashOlderThan string
+
+func init() {
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "Only purge messages trashed longer ago than this (e.g. 30d, 12h)")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(undeleteCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	database, toID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	messages, err := database.GetInbox(toID, db.StatusTrashed)
+	if err != nil {
+		return fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		output := InboxOutput{
+			Messages: make([]InboxMessageJSON, len(messages)),
+			Count:    len(messages),
+		}
+		for i := range messages {
+			output.Messages[i] = toInboxMessageJSON(&messages[i])
+		}
+		return PrintJSON(output)
+	}
+
+	// Text output
+	if len(messages) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	printMessageTable(messages, func(m *db.InboxMessage) string {
+		return strings.Join(m.ToIDs, ",")
+	})
+
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	cutoff := time.Now()
+	if trashOlderThan != "" {
+		d, err := parseDuration(trashOlderThan)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+		cutoff = cutoff.Add(-d)
+	}
+
+	database, toID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	purged, err := database.EmptyTrash(toID, cutoff)
+	if err != nil {
+		return err
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(TrashEmptyOutput{Purged: purged})
+	}
+
+	// Text output
+	fmt.Printf("✓ Permanently removed %d message(s)\n", purged)
+	return nil
+}
+
+func runUndelete(cmd *cobra.Command, args []string) error {
+	database, toID, err := openWithIdentity()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	messageID := args[0]
+
+	// Find message by prefix
+	msg, err := findMessageWithStatus(database, messageID, toID, db.StatusTrashed)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return fmt.Errorf("message not in trash: %s", messageID)
+	}
+
+	if _, err := database.Undelete(msg.ID, toID); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Restored %s to inbox\n", SafeShortID(msg.ID))
+	return nil
+}
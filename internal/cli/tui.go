@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,7 +18,7 @@ var tuiCmd = &cobra.Command{
 	Long: `Launch the interactive terminal UI for managing messages.
 
 The TUI provides:
-  - Inbox view with message list
+  - Inbox view with message list, refreshed as new mail arrives
   - Message reading pane
   - Compose new messages
   - Switch between mailboxes (admin mode)
@@ -44,12 +45,16 @@ func runTUI(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	tryRules(database, cfg)
 
 	// Resolve identity (or use first available role)
 	var currentIdentity string
 	res, err := identity.Resolve(cfg)
 	if err == nil && res != nil {
 		currentIdentity = res.Identity
+		if err := useSession(database, res); err != nil {
+			return err
+		}
 	} else {
 		// Default to first role or "user"
 		if len(cfg.Agents.Roles) > 0 {
@@ -61,6 +66,16 @@ func runTUI(cmd *cobra.Command, args []string) error {
 
 	// Create and run TUI
 	model := tui.NewModel(database, cfg, currentIdentity)
+
+	// Refresh live as mail arrives
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := database.Subscribe(ctx, pollInterval(cfg, 0))
+	if err != nil {
+		return err
+	}
+	model.WatchChanges(changes)
+
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
@@ -17,10 +17,12 @@ var useCmd = &cobra.Command{
 Use with source to apply:
   source <(amail use dev)
 
-This sets the AMAIL_IDENTITY environment variable.
+This sets the AMAIL_IDENTITY environment variable. Name an instance such
+as dev#1 to run several sessions of a role, each with its own read state.
 
 Examples:
   source <(amail use dev)
+  source <(amail use dev#1)
   source <(amail use pm)
   source <(amail use user)`,
 	Args: cobra.ExactArgs(1),
@@ -39,7 +41,7 @@ func runUse(cmd *cobra.Command, args []string) error {
 	if err == nil {
 		// Load config to validate role
 		cfg, err := config.LoadProject(root)
-		if err == nil && !cfg.IsValidRole(role) {
+		if err == nil && !cfg.IsValidRole(identity.RoleOf(role)) {
 			// Print warning to stderr so it doesn't interfere with source
 			fmt.Fprintf(cmd.ErrOrStderr(), "# Warning: '%s' is not a defined role\n", role)
 			fmt.Fprintf(cmd.ErrOrStderr(), "# Available roles: %s\n", formatRoles(cfg))
@@ -1,20 +1,16 @@
 package cli
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
-)
 
-// generateID creates a short random ID for messages
-func generateID() string {
-	bytes := make([]byte, 8)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
-}
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
 
 // SafeShortID returns the first 8 characters of an ID, or the full ID if shorter
 func SafeShortID(id string) string {
@@ -73,6 +69,168 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%d hours", hours)
 }
 
+// formatSize formats a byte count in a human-readable way
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseDuration parses a Go duration string, additionally accepting day (d)
+// and week (w) units as a leading component, e.g. "7d", "2w", "1d12h"
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	// Split off a leading <n>d or <n>w component
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i > 0 && i < len(s) && (s[i] == 'd' || s[i] == 'w') {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		unit := 24 * time.Hour
+		if s[i] == 'w' {
+			unit = 7 * 24 * time.Hour
+		}
+		d := time.Duration(n) * unit
+
+		if rest := s[i+1:]; rest != "" {
+			extra, err := time.ParseDuration(rest)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration: %s", s)
+			}
+			d += extra
+		}
+		return d, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
+
+// Accepted absolute time formats, interpreted in local time unless they carry a zone
+var timeFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime parses an absolute date/time in one of the accepted formats
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeFormats {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s (use YYYY-MM-DD, YYYY-MM-DDTHH:MM or RFC3339)", s)
+}
+
+// parseSince parses either a relative duration ("24h", "7d") counted back
+// from now, or an absolute date/time
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := parseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := parseTime(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since value: %s (use a duration like 24h or 7d, or a date)", s)
+	}
+	return t, nil
+}
+
+// parseDeliverAt parses the --at and --in scheduling flags into a delivery
+// time after now. Returns nil when neither is set.
+func parseDeliverAt(at, in string, now time.Time) (*time.Time, error) {
+	var t time.Time
+	switch {
+	case at != "" && in != "":
+		return nil, fmt.Errorf("use either --at or --in, not both")
+	case at != "":
+		parsed, err := parseTime(at)
+		if err != nil {
+			return nil, err
+		}
+		t = parsed
+	case in != "":
+		d, err := parseDuration(in)
+		if err != nil {
+			return nil, err
+		}
+		t = now.Add(d)
+	default:
+		return nil, nil
+	}
+
+	if !t.After(now) {
+		return nil, fmt.Errorf("delivery time %s is not in the future", t.Format("2006-01-02 15:04"))
+	}
+	return &t, nil
+}
+
+// parseUntil parses either a relative duration ("1h", "2d") counted forward
+// from now, a time of day ("18:00", the next one after now), or an absolute
+// date/time, which must be after now
+func parseUntil(s string, now time.Time) (time.Time, error) {
+	t, err := parseTime(s)
+	if d, durErr := parseDuration(s); durErr == nil {
+		t, err = now.Add(d), nil
+	}
+	if clock, clockErr := time.ParseInLocation("15:04", strings.TrimSpace(s), time.Local); clockErr == nil {
+		t, err = time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local), nil
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s (use a duration like 1h or 2d, a time like 18:00, or a date)", s)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("%s is not in the future", t.Format("2006-01-02 15:04"))
+	}
+	return t, nil
+}
+
+// parseDue parses the --due deadline of a request. A duration counts from
+// when the message is delivered. Returns nil if due is empty.
+func parseDue(due, msgType string, deliverAt *time.Time, now time.Time) (*time.Time, error) {
+	if due == "" {
+		return nil, nil
+	}
+	if msgType != "request" {
+		return nil, fmt.Errorf("--due only applies to requests (use -t request)")
+	}
+
+	base := now
+	if deliverAt != nil {
+		base = *deliverAt
+	}
+	t, err := parseUntil(due, base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --due: %w", err)
+	}
+	return &t, nil
+}
+
 // truncate truncates a string to maxLen runes and adds "..." if truncated
 // Uses rune count instead of byte count for proper UTF-8 handling
 func truncate(s string, maxLen int) string {
@@ -121,3 +279,44 @@ func validateMsgType(msgType string) error {
 	}
 	return nil
 }
+
+// openWithIdentity opens the project and resolves the caller's identity
+func openWithIdentity() (*db.DB, string, error) {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		database.Close()
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		database.Close()
+		return nil, "", err
+	}
+	if err := useSession(database, res); err != nil {
+		database.Close()
+		return nil, "", err
+	}
+
+	return database, res.Identity, nil
+}
+
+// useSession records res as present and makes read and notified state on
+// database apply to res's role instance, if it names one
+func useSession(database *db.DB, res *identity.Resolution) error {
+	if err := database.RecordPresence(newPresence(res)); err != nil {
+		return err
+	}
+	if res.Instance == "" {
+		return nil
+	}
+	return database.UseSession(res.Identity, res.Instance)
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"testing"
+	"time"
 )
 
 func TestSafeShortID(t *testing.T) {
@@ -70,24 +71,6 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
-func TestGenerateID(t *testing.T) {
-	// Test that generateID returns a 16-character hex string
-	id := generateID()
-	if len(id) != 16 {
-		t.Errorf("generateID() returned %q with length %d, want 16", id, len(id))
-	}
-
-	// Test uniqueness (generate multiple IDs)
-	ids := make(map[string]bool)
-	for i := 0; i < 100; i++ {
-		id := generateID()
-		if ids[id] {
-			t.Errorf("generateID() returned duplicate ID: %s", id)
-		}
-		ids[id] = true
-	}
-}
-
 func TestParseRecipients(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -119,3 +102,170 @@ func TestParseRecipients(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"2h", 2 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"1d12h", 36 * time.Hour, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"soon", 0, true},
+		{"1d12x", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"7d", now.Add(-7 * 24 * time.Hour), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"2026-03-01T09:30", time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local), false},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSince(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.input); got != tt.expected {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestParseDeliverAt(t *testing.T) {
+	now := time.Date(2026, 10, 16, 17, 0, 0, 0, time.Local)
+
+	got, err := parseDeliverAt("", "", now)
+	if err != nil || got != nil {
+		t.Errorf("no flags: got %v, %v; want nil", got, err)
+	}
+
+	got, err = parseDeliverAt("", "2h", now)
+	if err != nil || !got.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("--in 2h: got %v, %v", got, err)
+	}
+
+	got, err = parseDeliverAt("2026-10-17T09:00", "", now)
+	if err != nil || !got.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("--at: got %v, %v", got, err)
+	}
+
+	errorCases := []struct{ at, in string }{
+		{"2026-10-17T09:00", "2h"},
+		{"2026-10-16T09:00", ""},
+		{"", "-1h"},
+		{"tomorrow", ""},
+	}
+	for _, tc := range errorCases {
+		if _, err := parseDeliverAt(tc.at, tc.in, now); err == nil {
+			t.Errorf("parseDeliverAt(%q, %q) expected error", tc.at, tc.in)
+		}
+	}
+}
+
+func TestParseUntil(t *testing.T) {
+	now := time.Date(2026, 10, 16, 17, 0, 0, 0, time.Local)
+
+	got, err := parseUntil("1h", now)
+	if err != nil || !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("parseUntil(1h) = %v, %v", got, err)
+	}
+
+	got, err = parseUntil("2026-10-17T09:00", now)
+	if err != nil || !got.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("parseUntil(date) = %v, %v", got, err)
+	}
+
+	got, err = parseUntil("18:00", now)
+	if err != nil || !got.Equal(time.Date(2026, 10, 16, 18, 0, 0, 0, time.Local)) {
+		t.Errorf("parseUntil(18:00) = %v, %v", got, err)
+	}
+
+	got, err = parseUntil("09:00", now)
+	if err != nil || !got.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("parseUntil(09:00) = %v, %v", got, err)
+	}
+
+	for _, input := range []string{"", "soon", "2026-10-16T09:00", "-1h"} {
+		if _, err := parseUntil(input, now); err == nil {
+			t.Errorf("parseUntil(%q) expected error", input)
+		}
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2026, 10, 16, 17, 0, 0, 0, time.Local)
+
+	got, err := parseDue("", "message", nil, now)
+	if err != nil || got != nil {
+		t.Errorf("no --due: got %v, %v; want nil", got, err)
+	}
+
+	got, err = parseDue("4h", "request", nil, now)
+	if err != nil || !got.Equal(now.Add(4*time.Hour)) {
+		t.Errorf("--due 4h: got %v, %v", got, err)
+	}
+
+	// Durations count from a scheduled delivery
+	deliverAt := now.Add(24 * time.Hour)
+	got, err = parseDue("4h", "request", &deliverAt, now)
+	if err != nil || !got.Equal(deliverAt.Add(4*time.Hour)) {
+		t.Errorf("--due 4h with --in 1d: got %v, %v", got, err)
+	}
+
+	if _, err := parseDue("4h", "message", nil, now); err == nil {
+		t.Error("expected --due on a plain message to be rejected")
+	}
+	if _, err := parseDue("2026-10-16T09:00", "request", nil, now); err == nil {
+		t.Error("expected a past deadline to be rejected")
+	}
+}
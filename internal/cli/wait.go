@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Block until a matching message arrives",
+	Long: `Block until an unread message matching the filters is in your inbox,
+then mark it as read and print it like 'amail read'.
+
+Unread messages that are already waiting count, so a reply that lands
+between sending a request and calling wait is not missed.
+
+With --timeout, exits with code 124 if nothing matches in time.
+
+Examples:
+  amail wait --from dev
+  amail wait --reply-to abc123 --timeout 10m
+  amail wait --thread abc123 --type response
+  amail wait --priority urgent`,
+	Args: cobra.NoArgs,
+	RunE: runWait,
+}
+
+var (
+	waitFrom     string
+	waitThread   string
+	waitReplyTo  string
+	waitType     string
+	waitPriority string
+	waitTimeout  string
+	waitInterval int
+)
+
+func init() {
+	waitCmd.Flags().StringVar(&waitFrom, "from", "", "Only messages from this sender")
+	waitCmd.Flags().StringVar(&waitThread, "thread", "", "Only messages in the thread of this message")
+	waitCmd.Flags().StringVar(&waitReplyTo, "reply-to", "", "Only direct replies to this message")
+	waitCmd.Flags().StringVarP(&waitType, "type", "t", "", "Only messages of this type")
+	waitCmd.Flags().StringVarP(&waitPriority, "priority", "p", "", "Only messages with this priority")
+	waitCmd.Flags().StringVar(&waitTimeout, "timeout", "", "Give up after this long (e.g. 30s, 10m); default waits forever")
+	waitCmd.Flags().IntVar(&waitInterval, "interval", 0, "Fallback polling interval in seconds (default from config)")
+	rootCmd.AddCommand(waitCmd)
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	if waitType != "" {
+		if err := validateMsgType(waitType); err != nil {
+			return err
+		}
+	}
+	if waitPriority != "" {
+		if err := validatePriority(waitPriority); err != nil {
+			return err
+		}
+	}
+
+	var timeout time.Duration
+	if waitTimeout != "" {
+		d, err := parseDuration(waitTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		timeout = d
+	}
+
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Resolve identity
+	res, err := identity.MustResolve(cfg)
+	if err != nil {
+		return err
+	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
+	toID := res.Identity
+
+	filter := waitFilter{
+		From:     waitFrom,
+		MsgType:  waitType,
+		Priority: waitPriority,
+	}
+	if waitThread != "" {
+		msg, err := findAnyMessage(database, waitThread)
+		if err != nil {
+			return err
+		}
+		filter.ThreadID = threadRoot(msg)
+	}
+	if waitReplyTo != "" {
+		msg, err := findAnyMessage(database, waitReplyTo)
+		if err != nil {
+			return err
+		}
+		filter.ReplyToID = msg.ID
+	}
+
+	msg, err := waitForMessage(database, toID, filter, timeout, pollInterval(cfg, waitInterval))
+	if err != nil {
+		return err
+	}
+
+	// Mark as read
+	if err := database.MarkRead(msg.ID, toID); err != nil {
+		return fmt.Errorf("failed to mark as read: %w", err)
+	}
+	msg.Status = "read"
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(toReadOutput(msg))
+	}
+
+	// Text output
+	displayMessage(msg)
+
+	return nil
+}
+
+// waitFilter selects which incoming messages satisfy a wait. Empty fields
+// match anything.
+type waitFilter struct {
+	From      string
+	ThreadID  string
+	ReplyToID string
+	MsgType   string
+	Priority  string
+}
+
+// matches reports whether a message satisfies every set field of the filter
+func (f waitFilter) matches(m *db.InboxMessage) bool {
+	if f.From != "" && m.FromID != f.From {
+		return false
+	}
+	if f.ThreadID != "" && m.ID != f.ThreadID && (m.ThreadID == nil || *m.ThreadID != f.ThreadID) {
+		return false
+	}
+	if f.ReplyToID != "" && (m.ReplyToID == nil || *m.ReplyToID != f.ReplyToID) {
+		return false
+	}
+	if f.MsgType != "" && m.MsgType != f.MsgType {
+		return false
+	}
+	if f.Priority != "" && m.Priority != f.Priority {
+		return false
+	}
+	return true
+}
+
+// waitForMessage blocks until a message matching filter is in toID's unread
+// mail and returns the oldest match. The inbox is re-checked whenever the
+// mailbox changes, or every interval as a fallback. A zero timeout waits forever.
+func waitForMessage(database *db.DB, toID string, filter waitFilter, timeout, interval time.Duration) (*db.InboxMessage, error) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	// Subscribe before the first check so nothing arriving in between is missed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := database.Subscribe(ctx, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		messages, err := database.GetInbox(toID, db.StatusUnread)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get inbox: %w", err)
+		}
+
+		// Inbox is newest first; deliver the oldest match
+		for i := len(messages) - 1; i >= 0; i-- {
+			if filter.matches(&messages[i]) {
+				return &messages[i], nil
+			}
+		}
+
+		select {
+		case <-changes:
+		case <-deadline:
+			return nil, errTimeout("timed out after %s waiting for a matching message", timeout)
+		case <-sigChan:
+			return nil, fmt.Errorf("interrupted while waiting for a message")
+		}
+	}
+}
+
+// pollInterval returns the fallback polling interval from a flag in seconds,
+// defaulting to the configured watch interval
+func pollInterval(cfg *config.Config, flagSeconds int) time.Duration {
+	interval := cfg.Watch.Interval
+	if flagSeconds > 0 {
+		interval = flagSeconds
+	}
+	if interval < 1 {
+		interval = 2
+	}
+	return time.Duration(interval) * time.Second
+}
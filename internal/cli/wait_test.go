@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thirteen37/amail/internal/db"
+)
+
+func TestWaitFilterMatches(t *testing.T) {
+	root := "root1"
+	parent := "msg1"
+	msg := &db.InboxMessage{Message: db.Message{
+		ID:        "msg2",
+		FromID:    "dev",
+		Priority:  "high",
+		MsgType:   "response",
+		ThreadID:  &root,
+		ReplyToID: &parent,
+	}}
+
+	tests := []struct {
+		name   string
+		filter waitFilter
+		want   bool
+	}{
+		{"empty filter", waitFilter{}, true},
+		{"from match", waitFilter{From: "dev"}, true},
+		{"from mismatch", waitFilter{From: "qa"}, false},
+		{"thread match", waitFilter{ThreadID: "root1"}, true},
+		{"thread mismatch", waitFilter{ThreadID: "other"}, false},
+		{"thread root itself", waitFilter{ThreadID: "msg2"}, true},
+		{"reply-to match", waitFilter{ReplyToID: "msg1"}, true},
+		{"reply-to mismatch", waitFilter{ReplyToID: "root1"}, false},
+		{"type and priority", waitFilter{MsgType: "response", Priority: "high"}, true},
+		{"type mismatch", waitFilter{MsgType: "request"}, false},
+		{"priority mismatch", waitFilter{Priority: "urgent"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(msg); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForMessage(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "mail.db"))
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	// Nothing matches yet
+	_, err = waitForMessage(database, "dev", waitFilter{From: "pm"}, 50*time.Millisecond, 10*time.Millisecond)
+	if ExitCode(err) != ExitTimeout {
+		t.Fatalf("expected timeout exit code, got %v", err)
+	}
+
+	// A message arriving while waiting is returned
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		database.SendMessage(&db.Message{
+			ID: "msg1", FromID: "pm", Subject: "Hi", Body: "Body",
+			Priority: "normal", MsgType: "message", CreatedAt: time.Now(),
+		}, []string{"dev"})
+	}()
+
+	msg, err := waitForMessage(database, "dev", waitFilter{From: "pm"}, 5*time.Second, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("waitForMessage failed: %v", err)
+	}
+	if msg.ID != "msg1" {
+		t.Errorf("expected msg1, got %s", msg.ID)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if code := ExitCode(nil); code != 0 {
+		t.Errorf("ExitCode(nil) = %d, want 0", code)
+	}
+	if code := ExitCode(errors.New("boom")); code != 1 {
+		t.Errorf("ExitCode(plain) = %d, want 1", code)
+	}
+	wrapped := fmt.Errorf("wrapped: %w", errTimeout("timed out"))
+	if code := ExitCode(wrapped); code != ExitTimeout {
+		t.Errorf("ExitCode(timeout) = %d, want %d", code, ExitTimeout)
+	}
+}
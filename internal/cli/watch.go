@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -19,8 +20,9 @@ var watchCmd = &cobra.Command{
 	Short: "Watch inbox for new messages",
 	Long: `Watch your inbox and trigger notifications for new messages.
 
-Polls the database at a configurable interval and executes notification
-commands when new messages arrive.
+Executes notification commands as soon as new messages arrive. Changes
+are detected by watching the .amail directory, with a configurable
+fallback polling interval.
 
 Configure notifications in .amail/config.toml:
   [notify.default]
@@ -29,16 +31,32 @@ Configure notifications in .amail/config.toml:
   [notify.urgent]
   commands = ["terminal-notifier -title '🚨 {from}' -message '{body}'"]
 
+While it runs, watch keeps your role online in 'amail who'.
+
+Requests sent with --due are escalated once they are overdue: whichever
+watch sees one first sends its sender an urgent notification for each
+recipient still working on it, so the sender's own watch runs the urgent
+notification commands. Set escalate_to_user = true under [watch] to also
+send user a copy.
+
+In JSON mode, each new message and escalation is printed as one JSON
+object per line (NDJSON). Use 'amail events --follow' to stream activity
+for every role.
+
 Examples:
   amail watch
-  amail watch --interval 5`,
+  amail watch --interval 5
+  amail watch --json | jq -r .subject`,
 	RunE: runWatch,
 }
 
 var watchInterval int
 
+// heartbeatInterval is how often watch records that its role is online
+const heartbeatInterval = 30 * time.Second
+
 func init() {
-	watchCmd.Flags().IntVar(&watchInterval, "interval", 0, "Polling interval in seconds (default from config)")
+	watchCmd.Flags().IntVar(&watchInterval, "interval", 0, "Fallback polling interval in seconds (default from config)")
 	rootCmd.AddCommand(watchCmd)
 }
 
@@ -55,47 +73,75 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	tryRules(database, cfg)
 
 	// Resolve identity
 	res, err := identity.MustResolve(cfg)
 	if err != nil {
 		return err
 	}
+	if err := useSession(database, res); err != nil {
+		return err
+	}
 	toID := res.Identity
 
-	// Determine interval
-	interval := cfg.Watch.Interval
-	if watchInterval > 0 {
-		interval = watchInterval
-	}
-	if interval < 1 {
-		interval = 2
+	// Determine fallback interval
+	interval := pollInterval(cfg, watchInterval)
+
+	// Keep stdout a clean NDJSON stream in JSON mode
+	status := os.Stdout
+	if IsJSONOutput() {
+		status = os.Stderr
 	}
 
-	fmt.Printf("Watching inbox for %s (interval: %ds)\n", toID, interval)
-	fmt.Println("Press Ctrl+C to stop")
-	fmt.Println()
+	fmt.Fprintf(status, "Watching inbox for %s (fallback interval: %s)\n", toID, interval)
+	fmt.Fprintln(status, "Press Ctrl+C to stop")
+	fmt.Fprintln(status)
+
+	// Deadlines pass without the mailbox changing
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Heartbeat, so 'amail who' shows this role online
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
 
 	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
-	defer ticker.Stop()
+	// Wake up as soon as the mailbox changes
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := database.Subscribe(ctx, interval)
+	if err != nil {
+		return err
+	}
 
 	// Initial check
 	if err := checkAndNotify(database, cfg, toID); err != nil {
 		fmt.Fprintf(os.Stderr, "Error checking inbox: %v\n", err)
 	}
+	if err := escalateOverdue(database, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error escalating requests: %v\n", err)
+	}
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-changes:
 			if err := checkAndNotify(database, cfg, toID); err != nil {
 				fmt.Fprintf(os.Stderr, "Error checking inbox: %v\n", err)
 			}
+		case <-ticker.C:
+			if err := escalateOverdue(database, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error escalating requests: %v\n", err)
+			}
+		case <-heartbeat.C:
+			if err := database.RecordPresence(newPresence(res)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error recording presence: %v\n", err)
+			}
 		case <-sigChan:
-			fmt.Println("\nStopping watch...")
+			fmt.Fprintln(status, "\nStopping watch...")
 			return nil
 		}
 	}
@@ -110,8 +156,13 @@ func checkAndNotify(database *db.DB, cfg *config.Config, toID string) error {
 
 	// Notify for each new message
 	for _, msg := range messages {
-		// Execute notification commands if configured
-		commands := cfg.GetNotifyCommands(msg.Priority)
+		// Execute notification commands if configured; a rule may have
+		// picked a command set other than the priority's
+		set := msg.Priority
+		if msg.NotifySet != "" {
+			set = msg.NotifySet
+		}
+		commands := cfg.GetNotifyCommands(set)
 		if len(commands) > 0 {
 			notifyMsg := notify.FromInboxMessage(&msg)
 			for _, err := range notify.ExecuteAll(commands, notifyMsg) {
@@ -124,9 +175,99 @@ func checkAndNotify(database *db.DB, cfg *config.Config, toID string) error {
 			fmt.Fprintf(os.Stderr, "Failed to mark notified: %v\n", err)
 		}
 
+		if IsJSONOutput() {
+			if err := PrintNDJSON(toCheckMessageJSON(&msg)); err != nil {
+				return err
+			}
+			continue
+		}
 		fmt.Printf("[%s] New message from %s: %s\n",
 			time.Now().Format("15:04:05"), msg.FromID, msg.Subject)
 	}
 
 	return nil
 }
+
+// EscalationJSON is the watch NDJSON record for an escalated request
+type EscalationJSON struct {
+	ID        string `json:"id"`
+	ShortID   string `json:"short_id"`
+	From      string `json:"from"`
+	Subject   string `json:"subject"`
+	Recipient string `json:"recipient"`
+	DueAt     string `json:"due_at"`
+	Escalated bool   `json:"escalated"`
+}
+
+// escalateOverdue mails the sender of each request past due an urgent
+// notification, once per recipient still working on it, so the sender's
+// own watch runs the urgent notification commands
+func escalateOverdue(database *db.DB, cfg *config.Config) error {
+	overdue, err := database.GetAllOverdue(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range overdue {
+		for _, r := range msg.Recipients {
+			// Another watcher may have escalated it already
+			ok, err := database.MarkEscalated(msg.ID, r.ToID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			if err := sendEscalation(database, cfg, &msg, r); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to escalate %s: %v\n", SafeShortID(msg.ID), err)
+				continue
+			}
+
+			if IsJSONOutput() {
+				if err := PrintNDJSON(EscalationJSON{
+					ID:        msg.ID,
+					ShortID:   SafeShortID(msg.ID),
+					From:      msg.FromID,
+					Subject:   msg.Subject,
+					Recipient: r.ToID,
+					DueAt:     msg.DueAt.Format(time.RFC3339),
+					Escalated: true,
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("[%s] Overdue: %s has not finished %s from %s\n",
+				time.Now().Format("15:04:05"), r.ToID, msg.Subject, msg.FromID)
+		}
+	}
+
+	return nil
+}
+
+// sendEscalation sends the request's sender, and user if escalate_to_user
+// is set, an urgent notification from the late recipient in the request's
+// thread
+func sendEscalation(database *db.DB, cfg *config.Config, request *db.SentMessage, r db.Recipient) error {
+	threadID := request.ID
+	if request.ThreadID != nil {
+		threadID = *request.ThreadID
+	}
+	to := []string{request.FromID}
+	if cfg.Watch.EscalateToUser && request.FromID != "user" && r.ToID != "user" {
+		to = append(to, "user")
+	}
+	return database.SendMessage(&db.Message{
+		ID:      db.NewID(),
+		FromID:  r.ToID,
+		Subject: "Overdue: " + request.Subject,
+		Body: fmt.Sprintf("%s has not finished request %s (due %s, %s).",
+			r.ToID, SafeShortID(request.ID), request.DueAt.Local().Format("2006-01-02 15:04"), r.RequestState),
+		Priority:  "urgent",
+		MsgType:   "notification",
+		ThreadID:  &threadID,
+		ReplyToID: &request.ID,
+		CreatedAt: time.Now(),
+	}, to)
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+	"github.com/thirteen37/amail/internal/identity"
+)
+
+// Presence states
+const (
+	PresenceOnline  = "online"
+	PresenceIdle    = "idle"
+	PresenceOffline = "offline"
+)
+
+// WhoOutput is the JSON output structure for the who command
+type WhoOutput struct {
+	Roles []RolePresenceJSON `json:"roles"`
+}
+
+// RolePresenceJSON is the JSON representation of a role's presence
+type RolePresenceJSON struct {
+	Role       string         `json:"role"`
+	State      string         `json:"state"`
+	LastSeen   *string        `json:"last_seen,omitempty"`
+	Identities []PresenceJSON `json:"identities"`
+}
+
+// PresenceJSON is the JSON representation of one identity's presence
+type PresenceJSON struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Host     string `json:"host,omitempty"`
+	PID      int    `json:"pid,omitempty"`
+	Tmux     string `json:"tmux,omitempty"`
+	LastSeen string `json:"last_seen"`
+}
+
+var whoCmd = &cobra.Command{
+	Use:   "who",
+	Short: "Show which roles are online",
+	Long: `Show whether each role is online, idle or offline, from the last time
+any of its sessions ran amail. A running 'amail watch' keeps its role
+online.
+
+Thresholds are set in .amail/config.toml:
+  [presence]
+  online = 120    # seconds
+  offline = 1800  # seconds
+
+Examples:
+  amail who
+  amail who --json`,
+	Args: cobra.NoArgs,
+	RunE: runWho,
+}
+
+func init() {
+	rootCmd.AddCommand(whoCmd)
+}
+
+func runWho(cmd *cobra.Command, args []string) error {
+	// Open project
+	database, root, err := db.OpenProject()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	// Load config
+	cfg, err := config.LoadProject(root)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// The caller, if it has an identity, counts as online
+	res, err := identity.Resolve(cfg)
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		if err := useSession(database, res); err != nil {
+			return err
+		}
+	}
+
+	presence, err := database.GetPresence()
+	if err != nil {
+		return err
+	}
+	byRole := make(map[string][]db.Presence)
+	for _, p := range presence {
+		byRole[p.Role] = append(byRole[p.Role], p)
+	}
+
+	now := time.Now()
+	output := WhoOutput{Roles: []RolePresenceJSON{}}
+	for _, role := range cfg.AllRoles() {
+		entry := RolePresenceJSON{Role: role, State: PresenceOffline, Identities: []PresenceJSON{}}
+		for i, p := range byRole[role] {
+			// Rows are most recent first, so the first one decides
+			if i == 0 {
+				entry.State = presenceState(&p.LastSeen, now, cfg)
+				entry.LastSeen = formatOptionalTime(&p.LastSeen)
+			}
+			entry.Identities = append(entry.Identities, PresenceJSON{
+				Name:     p.Name,
+				State:    presenceState(&p.LastSeen, now, cfg),
+				Host:     p.Host,
+				PID:      p.PID,
+				Tmux:     p.Tmux,
+				LastSeen: p.LastSeen.Format(time.RFC3339),
+			})
+		}
+		output.Roles = append(output.Roles, entry)
+	}
+
+	// JSON output
+	if IsJSONOutput() {
+		return PrintJSON(output)
+	}
+
+	// Text output
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROLE\tSTATE\tLAST SEEN\tNAME\tHOST\tPID\tTMUX")
+	fmt.Fprintln(w, "----\t-----\t---------\t----\t----\t---\t----")
+	for _, r := range output.Roles {
+		if len(byRole[r.Role]) == 0 {
+			fmt.Fprintf(w, "%s\t%s\tnever\t-\t-\t-\t-\n", r.Role, r.State)
+			continue
+		}
+		for _, p := range byRole[r.Role] {
+			tmux := p.Tmux
+			if tmux == "" {
+				tmux = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				r.Role, presenceState(&p.LastSeen, now, cfg), formatTimeAgo(p.LastSeen),
+				p.Name, p.Host, p.PID, tmux)
+		}
+	}
+	w.Flush()
+
+	return nil
+}
+
+// presenceState classifies a role's last sign of life as online, idle or
+// offline. A role never seen is offline.
+func presenceState(lastSeen *time.Time, now time.Time, cfg *config.Config) string {
+	if lastSeen == nil {
+		return PresenceOffline
+	}
+	age := now.Sub(*lastSeen)
+	switch {
+	case age <= time.Duration(cfg.Presence.Online)*time.Second:
+		return PresenceOnline
+	case age <= time.Duration(cfg.Presence.Offline)*time.Second:
+		return PresenceIdle
+	}
+	return PresenceOffline
+}
+
+// newPresence describes this process as a sign of life from res
+func newPresence(res *identity.Resolution) db.Presence {
+	host, _ := os.Hostname()
+	return db.Presence{
+		Name:     res.Name(),
+		Role:     res.Identity,
+		Host:     host,
+		PID:      os.Getpid(),
+		Tmux:     identity.GetTmuxSession(),
+		LastSeen: time.Now(),
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thirteen37/amail/internal/config"
+	"github.com/thirteen37/amail/internal/db"
+)
+
+func TestPresenceState(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Presence.Online = 60
+	cfg.Presence.Offline = 600
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		ago      time.Duration
+		never    bool
+		expected string
+	}{
+		{"just now", 0, false, PresenceOnline},
+		{"at online limit", time.Minute, false, PresenceOnline},
+		{"idle", 5 * time.Minute, false, PresenceIdle},
+		{"at offline limit", 10 * time.Minute, false, PresenceIdle},
+		{"offline", time.Hour, false, PresenceOffline},
+		{"never seen", 0, true, PresenceOffline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lastSeen *time.Time
+			if !tt.never {
+				seen := now.Add(-tt.ago)
+				lastSeen = &seen
+			}
+			if got := presenceState(lastSeen, now, cfg); got != tt.expected {
+				t.Errorf("presenceState() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRerouteOffline(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "mail.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer database.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Roles = []string{"pm", "dev", "qa", "research"}
+	now := time.Now()
+	for role, ago := range map[string]time.Duration{"dev": 2 * time.Hour, "qa": time.Minute, "pm": 3 * time.Hour} {
+		p := db.Presence{Name: role, Role: role, LastSeen: now.Add(-ago)}
+		if err := database.RecordPresence(p); err != nil {
+			t.Fatalf("RecordPresence failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		recipients   []string
+		fallback     string
+		wantRoles    []string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{"online", []string{"qa"}, "", []string{"qa"}, 0, false},
+		{"never seen", []string{"research"}, "", []string{"research"}, 0, false},
+		{"user not checked", []string{"user"}, "", []string{"user"}, 0, false},
+		{"offline warns", []string{"dev"}, "", []string{"dev"}, 1, false},
+		{"offline reroutes", []string{"dev"}, "research", []string{"research"}, 1, false},
+		{"reroute dedupes", []string{"dev", "qa"}, "qa", []string{"qa"}, 1, false},
+		{"fallback to sender", []string{"dev"}, "pm", nil, 1, true},
+		{"fallback itself offline", []string{"dev"}, "dev", []string{"dev"}, 1, false},
+		{"unknown fallback", []string{"dev"}, "nobody", nil, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, warnings, err := rerouteOffline(database, cfg, tt.recipients, "pm", tt.fallback, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("rerouteOffline() expected error, got %v", roles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("rerouteOffline() error = %v", err)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Errorf("rerouteOffline() roles = %v, want %v", roles, tt.wantRoles)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("rerouteOffline() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thirteen37/amail/internal/config"
@@ -11,10 +12,12 @@ import (
 
 // WhoamiOutput is the JSON output structure for the whoami command
 type WhoamiOutput struct {
-	Identity   *string  `json:"identity"`
-	Source     string   `json:"source,omitempty"`
-	Valid      bool     `json:"valid"`
-	ValidRoles []string `json:"valid_roles,omitempty"`
+	Identity   *string   `json:"identity"`
+	Instance   string    `json:"instance,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Valid      bool      `json:"valid"`
+	ValidRoles []string  `json:"valid_roles,omitempty"`
+	Away       *AwayJSON `json:"away,omitempty"`
 }
 
 var whoamiCmd = &cobra.Command{
@@ -27,6 +30,9 @@ Identity is resolved in this order:
   2. tmux session name mapping from config
   3. Not set (prompts to register)
 
+An identity such as dev#1 is an instance of the dev role: it receives
+dev's mail but keeps its own read and notified state.
+
 Examples:
   amail whoami`,
 	RunE: runWhoami,
@@ -55,6 +61,16 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Away state is informational, so a database that can't be opened
+	// is not an error here
+	var away *db.Away
+	if res != nil {
+		if database, err := db.Open(db.DBPath(root)); err == nil {
+			away, _ = database.GetAway(res.Identity, time.Now())
+			database.Close()
+		}
+	}
+
 	// JSON output
 	if IsJSONOutput() {
 		output := WhoamiOutput{
@@ -62,8 +78,10 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		}
 		if res != nil {
 			output.Identity = &res.Identity
+			output.Instance = res.Instance
 			output.Source = res.Source
 			output.Valid = cfg.IsValidRole(res.Identity)
+			output.Away = toAwayJSON(away)
 		} else {
 			output.Valid = false
 		}
@@ -93,8 +111,14 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("%s\n", res.Identity)
+	fmt.Printf("%s\n", res.Name())
+	if res.Instance != "" {
+		fmt.Printf("  (instance of %s)\n", res.Identity)
+	}
 	fmt.Printf("  (from %s)\n", res.Source)
+	if away != nil {
+		fmt.Printf("  (%s)\n", formatAway(away))
+	}
 
 	// Check if valid role
 	if !cfg.IsValidRole(res.Identity) {
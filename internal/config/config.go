@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,11 +16,16 @@ type Config struct {
 	Identity IdentityConfig          `toml:"identity"`
 	Watch    WatchConfig             `toml:"watch"`
 	Notify   map[string]NotifyConfig `toml:"notify"`
+	Serve    ServeConfig             `toml:"serve"`
+	Queue    QueueConfig             `toml:"queue"`
+	Presence PresenceConfig          `toml:"presence"`
+	Rules    []RuleConfig            `toml:"rules"`
 }
 
 // AgentsConfig defines the agent roles for the project
 type AgentsConfig struct {
 	Roles []string `toml:"roles"`
+	Pools []string `toml:"pools"` // roles whose mail is claimed by one session at a time
 }
 
 // IdentityConfig handles identity mapping
@@ -29,7 +35,48 @@ type IdentityConfig struct {
 
 // WatchConfig defines watch/polling settings
 type WatchConfig struct {
-	Interval int `toml:"interval"`
+	Interval       int  `toml:"interval"`
+	EscalateToUser bool `toml:"escalate_to_user"` // also send overdue requests to user
+}
+
+// ServeConfig defines settings for the HTTP API server
+type ServeConfig struct {
+	Listen string            `toml:"listen"`
+	Tokens map[string]string `toml:"tokens"` // role -> bearer token
+}
+
+// QueueConfig defines settings for claiming mail sent to pool roles
+type QueueConfig struct {
+	Lease int `toml:"lease"` // seconds before an unacked claim is redelivered
+}
+
+// PresenceConfig defines when a role counts as online, idle or offline
+type PresenceConfig struct {
+	Online  int `toml:"online"`  // seconds since last seen to count as online
+	Offline int `toml:"offline"` // seconds since last seen to count as offline
+}
+
+// RuleConfig is a [[rules]] block applied to incoming mail as it is
+// delivered. Every condition set must match; an empty rule matches all
+// mail. The actions then apply to the matching recipient's copy.
+type RuleConfig struct {
+	Name string `toml:"name"`
+
+	// Conditions
+	From     string `toml:"from"`     // sender role
+	To       string `toml:"to"`       // recipient role
+	Subject  string `toml:"subject"`  // regular expression
+	Body     string `toml:"body"`     // regular expression
+	Priority string `toml:"priority"` // low, normal, high or urgent
+	Type     string `toml:"type"`     // message, request, response or notification
+
+	// Actions
+	Label       []string `toml:"label"`
+	Archive     bool     `toml:"archive"`
+	MarkRead    bool     `toml:"mark_read"`
+	ForwardTo   string   `toml:"forward_to"`
+	SetPriority string   `toml:"set_priority"`
+	Notify      string   `toml:"notify"` // [notify.<name>] command set used by watch
 }
 
 // NotifyConfig defines notification commands for a priority level
@@ -55,6 +102,17 @@ func DefaultConfig() *Config {
 				Commands: []string{"echo '📬 New message from {from}: {subject}'"},
 			},
 		},
+		Serve: ServeConfig{
+			Listen: "127.0.0.1:7777",
+			Tokens: make(map[string]string),
+		},
+		Queue: QueueConfig{
+			Lease: 600,
+		},
+		Presence: PresenceConfig{
+			Online:  120,
+			Offline: 1800,
+		},
 	}
 }
 
@@ -124,6 +182,17 @@ func (c *Config) IsValidRole(role string) bool {
 	return false
 }
 
+// IsPool reports whether role is a pool role, whose mail is claimed by one
+// session at a time instead of shared
+func (c *Config) IsPool(role string) bool {
+	for _, p := range c.Agents.Pools {
+		if p == role {
+			return true
+		}
+	}
+	return false
+}
+
 // ResolveGroup resolves a group name (with @ prefix) to its members
 // Returns nil if not a group or group not found
 func (c *Config) ResolveGroup(name string, currentIdentity string) []string {
@@ -170,6 +239,19 @@ func (c *Config) GetNotifyCommands(priority string) []string {
 	return nil
 }
 
+// RoleForToken returns the role whose API bearer token matches token, or ""
+func (c *Config) RoleForToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	for role, t := range c.Serve.Tokens {
+		if t != "" && subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return role
+		}
+	}
+	return ""
+}
+
 // GenerateDefaultConfigContent generates a default config file content
 func GenerateDefaultConfigContent(roles []string) string {
 	content := `# amail project configuration
@@ -185,6 +267,7 @@ roles = [`
 	}
 
 	content += `]
+# pools = ["dev"]  # roles whose sessions claim mail one at a time (amail claim)
 
 [groups]
 # Define custom groups
@@ -197,7 +280,8 @@ roles = [`
 # "myproject-pm" = "pm"
 
 [watch]
-interval = 2  # polling interval in seconds
+interval = 2  # fallback polling interval in seconds (changes are picked up immediately)
+# escalate_to_user = true  # also send urgent mail to user when a request is overdue
 
 [notify.default]
 commands = [
@@ -213,6 +297,27 @@ commands = [
 commands = [
   "echo '🚨 URGENT from {from}: {subject}'"
 ]
+
+[queue]
+lease = 600  # seconds a claim lasts before an unacked message is redelivered
+
+[presence]
+online = 120    # seconds since a role was last seen to count as online (amail who)
+offline = 1800  # seconds after which it counts as offline; send warns or uses --fallback
+
+# Rules run on incoming mail as it is delivered (amail rules test <id>)
+# [[rules]]
+# name = "quiet notifications"
+# type = "notification"
+# label = ["fyi"]
+# mark_read = true
+
+[serve]
+listen = "127.0.0.1:7777"  # address for 'amail serve'
+
+[serve.tokens]
+# Bearer tokens for the HTTP API, one per role (e.g. openssl rand -hex 32)
+# dev = "..."
 `
 	return content
 }
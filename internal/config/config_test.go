@@ -74,6 +74,44 @@ func TestLoadAndSave(t *testing.T) {
 	}
 }
 
+func TestLoadRules(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[[rules]]
+name = "quiet notifications"
+type = "notification"
+label = ["fyi", "bots"]
+mark_read = true
+
+[[rules]]
+from = "qa"
+subject = "(?i)flaky"
+forward_to = "dev"
+set_priority = "low"
+notify = "quiet"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(cfg.Rules))
+	}
+	first := cfg.Rules[0]
+	if first.Name != "quiet notifications" || first.Type != "notification" || !first.MarkRead || len(first.Label) != 2 {
+		t.Errorf("unexpected first rule: %+v", first)
+	}
+	second := cfg.Rules[1]
+	if second.From != "qa" || second.Subject != "(?i)flaky" || second.ForwardTo != "dev" ||
+		second.SetPriority != "low" || second.Notify != "quiet" {
+		t.Errorf("unexpected second rule: %+v", second)
+	}
+}
+
 func TestAllRoles(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Agents.Roles = []string{"pm", "dev", "qa"}
@@ -122,6 +160,19 @@ func TestIsValidRole(t *testing.T) {
 	}
 }
 
+func TestIsPool(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Roles = []string{"pm", "dev", "qa"}
+	cfg.Agents.Pools = []string{"dev"}
+
+	if !cfg.IsPool("dev") {
+		t.Error("expected dev to be a pool")
+	}
+	if cfg.IsPool("pm") || cfg.IsPool("user") {
+		t.Error("expected only dev to be a pool")
+	}
+}
+
 func TestResolveGroup(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Agents.Roles = []string{"pm", "dev", "qa"}
@@ -191,6 +242,31 @@ func TestGetNotifyCommands(t *testing.T) {
 	}
 }
 
+func TestRoleForToken(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Serve.Tokens = map[string]string{
+		"dev": "dev-secret",
+		"pm":  "pm-secret",
+		"qa":  "",
+	}
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{"dev-secret", "dev"},
+		{"pm-secret", "pm"},
+		{"wrong", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.RoleForToken(tt.token); got != tt.want {
+			t.Errorf("RoleForToken(%q) = %q, want %q", tt.token, got, tt.want)
+		}
+	}
+}
+
 func TestGenerateDefaultConfigContent(t *testing.T) {
 	content := GenerateDefaultConfigContent([]string{"pm", "dev", "qa"})
 
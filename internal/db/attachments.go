@@ -0,0 +1,222 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// blobGracePeriod protects freshly stored blobs from garbage collection
+// while the message that references them is still being sent
+const blobGracePeriod = time.Hour
+
+// Attachment is a file attached to a message. Its contents are stored once
+// per distinct hash under the blobs directory.
+type Attachment struct {
+	MessageID string
+	Name      string
+	Hash      string
+	Size      int64
+}
+
+// BlobsDir returns the directory holding attachment contents
+func (db *DB) BlobsDir() string {
+	return filepath.Join(filepath.Dir(db.path), "blobs")
+}
+
+// BlobPath returns the path of the blob with the given hash
+func (db *DB) BlobPath(hash string) string {
+	return filepath.Join(db.BlobsDir(), hash[:2], hash)
+}
+
+// StoreBlob copies r into the blob store and returns its hash and size.
+// Storing the same content twice keeps a single copy.
+func (db *DB) StoreBlob(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(db.BlobsDir(), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create blobs directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(db.BlobsDir(), ".tmp-")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	path := db.BlobPath(hash)
+
+	// Already stored: refresh its mtime so garbage collection leaves it alone
+	// until the referencing message is committed
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return "", 0, fmt.Errorf("failed to touch blob: %w", err)
+		}
+		return hash, size, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return hash, size, nil
+}
+
+// OpenBlob opens the contents of the blob with the given hash
+func (db *DB) OpenBlob(hash string) (*os.File, error) {
+	f, err := os.Open(db.BlobPath(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob %s: %w", hash, err)
+	}
+	return f, nil
+}
+
+// GetAttachments returns the attachments of a message, in name order
+func (db *DB) GetAttachments(messageID string) ([]Attachment, error) {
+	result, err := db.getAttachmentsForMessages([]string{messageID})
+	if err != nil {
+		return nil, err
+	}
+	return result[messageID], nil
+}
+
+// getAttachmentsForMessages returns attachments for multiple messages in a single query
+func (db *DB) getAttachmentsForMessages(messageIDs []string) (map[string][]Attachment, error) {
+	if len(messageIDs) == 0 {
+		return make(map[string][]Attachment), nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	query := fmt.Sprintf(`
+		SELECT message_id, name, hash, size FROM attachments
+		WHERE message_id IN (%s)
+		ORDER BY message_id, name`,
+		placeholders,
+	)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]Attachment)
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.MessageID, &a.Name, &a.Hash, &a.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		result[a.MessageID] = append(result[a.MessageID], a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachment rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// CollectGarbage removes blobs that no attachment references. Blobs newer
+// than the grace period are kept, since a send may be about to reference
+// them. Returns the number of blobs removed.
+func (db *DB) CollectGarbage() (int, error) {
+	rows, err := db.conn.Query(`SELECT DISTINCT hash FROM attachments`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	referenced := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		referenced[hash] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating attachment rows: %w", err)
+	}
+
+	cutoff := time.Now().Add(-blobGracePeriod)
+	removed := 0
+	err = filepath.WalkDir(db.BlobsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || referenced[d.Name()] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to collect garbage: %w", err)
+	}
+
+	return removed, nil
+}
+
+// removeBlobs deletes the blobs among hashes that no attachment references
+// any more. Blobs stored or reused after since are kept, since a send may
+// be about to reference them.
+func (db *DB) removeBlobs(hashes []string, since time.Time) error {
+	for _, hash := range hashes {
+		var referenced bool
+		err := db.conn.QueryRow(`SELECT EXISTS (SELECT 1 FROM attachments WHERE hash = ?)`, hash).Scan(&referenced)
+		if err != nil {
+			return fmt.Errorf("failed to query attachments: %w", err)
+		}
+		if referenced {
+			continue
+		}
+
+		path := db.BlobPath(hash)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat blob %s: %w", hash, err)
+		}
+		if info.ModTime().After(since) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove blob %s: %w", hash, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreBlob(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	hash, size, err := db.StoreBlob(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("StoreBlob failed: %v", err)
+	}
+	if hash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("unexpected hash %s", hash)
+	}
+	if size != 5 {
+		t.Errorf("expected size 5, got %d", size)
+	}
+
+	// Same content is stored once
+	hash2, _, err := db.StoreBlob(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("StoreBlob failed: %v", err)
+	}
+	if hash2 != hash {
+		t.Errorf("expected same hash for same content, got %s and %s", hash, hash2)
+	}
+
+	f, err := db.OpenBlob(hash)
+	if err != nil {
+		t.Fatalf("OpenBlob failed: %v", err)
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	if string(data) != "hello" {
+		t.Errorf("expected blob contents 'hello', got %q", data)
+	}
+}
+
+func TestSendMessageWithAttachments(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	hash, size, _ := db.StoreBlob(strings.NewReader("log line"))
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Logs",
+		Body:      "See attached",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+		Attachments: []Attachment{
+			{Name: "test.log", Hash: hash, Size: size},
+			{Name: "build.log", Hash: hash, Size: size},
+		},
+	}
+	if err := db.SendMessage(msg, []string{"dev"}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	got, err := db.GetMessage("msg001")
+	if err != nil {
+		t.Fatalf("GetMessage failed: %v", err)
+	}
+	if len(got.Attachments) != 2 || got.Attachments[0].Name != "build.log" {
+		t.Fatalf("expected 2 attachments sorted by name, got %+v", got.Attachments)
+	}
+
+	inbox, err := db.GetInbox("dev", StatusUnread)
+	if err != nil {
+		t.Fatalf("GetInbox failed: %v", err)
+	}
+	if len(inbox) != 1 || len(inbox[0].Attachments) != 2 {
+		t.Errorf("expected inbox message with 2 attachments, got %+v", inbox)
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	kept, size, _ := db.StoreBlob(strings.NewReader("referenced"))
+	orphan, _, _ := db.StoreBlob(strings.NewReader("orphan"))
+	fresh, _, _ := db.StoreBlob(strings.NewReader("fresh orphan"))
+
+	db.SendMessage(&Message{
+		ID:          "msg001",
+		FromID:      "pm",
+		Subject:     "Logs",
+		Body:        "See attached",
+		Priority:    "normal",
+		MsgType:     "message",
+		CreatedAt:   time.Now(),
+		Attachments: []Attachment{{Name: "a.txt", Hash: kept, Size: size}},
+	}, []string{"dev"})
+
+	// Age everything except the fresh orphan past the grace period
+	old := time.Now().Add(-2 * blobGracePeriod)
+	os.Chtimes(db.BlobPath(kept), old, old)
+	os.Chtimes(db.BlobPath(orphan), old, old)
+
+	removed, err := db.CollectGarbage()
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 blob removed, got %d", removed)
+	}
+
+	for hash, wantExists := range map[string]bool{kept: true, orphan: false, fresh: true} {
+		_, err := os.Stat(db.BlobPath(hash))
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("blob %s exists = %v, want %v", hash[:8], exists, wantExists)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Away is a role's out-of-office state. While it lasts, mail to the role is
+// forwarded to ForwardTo, if set, and the sender gets an auto-reply.
+type Away struct {
+	Role      string
+	ForwardTo string     // Delegate receiving the role's mail; empty for none
+	Message   string     // Auto-reply body; empty for a default
+	Until     *time.Time // When the role is back; nil until 'amail back'
+	StartedAt time.Time
+}
+
+// SetAway marks a role as away, replacing any earlier away state. Senders
+// get auto-replies afresh.
+func (db *DB) SetAway(a Away) error {
+	var until *string
+	if a.Until != nil {
+		t := sqlTime(*a.Until)
+		until = &t
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO away (role, forward_to, message, until, started_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(role) DO UPDATE SET
+		    forward_to = excluded.forward_to, message = excluded.message,
+		    until = excluded.until, started_at = excluded.started_at`,
+		a.Role, a.ForwardTo, a.Message, until, a.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to set away: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM away_replies WHERE role = ?`, a.Role); err != nil {
+		return fmt.Errorf("failed to reset auto-replies: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ClearAway ends a role's away state. Returns false if it was not away.
+func (db *DB) ClearAway(role string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM away WHERE role = ?`, role)
+	if err != nil {
+		return false, fmt.Errorf("failed to clear away: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM away_replies WHERE role = ?`, role); err != nil {
+		return false, fmt.Errorf("failed to reset auto-replies: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// GetAway returns a role's away state, or nil if it is not away at now
+func (db *DB) GetAway(role string, now time.Time) (*Away, error) {
+	return getAway(db.conn, role, now)
+}
+
+// GetAllAway returns every role away at now, keyed by role
+func (db *DB) GetAllAway(now time.Time) (map[string]*Away, error) {
+	rows, err := db.conn.Query(`
+		SELECT role, forward_to, message, until, started_at FROM away
+		WHERE until IS NULL OR until > ?`, sqlTime(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query away: %w", err)
+	}
+	defer rows.Close()
+
+	away := make(map[string]*Away)
+	for rows.Next() {
+		a, err := scanAway(rows)
+		if err != nil {
+			return nil, err
+		}
+		away[a.Role] = a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating away rows: %w", err)
+	}
+	return away, nil
+}
+
+func getAway(q queryRower, role string, now time.Time) (*Away, error) {
+	a, err := scanAway(q.QueryRowContext(context.Background(), `
+		SELECT role, forward_to, message, until, started_at FROM away
+		WHERE role = ? AND (until IS NULL OR until > ?)`, role, sqlTime(now)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+func scanAway(row interface{ Scan(...interface{}) error }) (*Away, error) {
+	var a Away
+	var until sql.NullTime
+	if err := row.Scan(&a.Role, &a.ForwardTo, &a.Message, &until, &a.StartedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan away: %w", err)
+	}
+	if until.Valid {
+		a.Until = &until.Time
+	}
+	return &a, nil
+}
+
+// handleAway forwards msg to the delegate of each recipient who is away
+// and sends its sender that recipient's auto-reply, once per away period.
+// Both join msg's thread. Forwards get the delegate's rules; notifications
+// get no auto-reply. Neither triggers away handling itself.
+func handleAway(tx *sql.Tx, rules []Rule, msg *Message, recipients []string, now time.Time) error {
+	threadID := msg.ID
+	if msg.ThreadID != nil {
+		threadID = *msg.ThreadID
+	}
+
+	for _, role := range recipients {
+		a, err := getAway(tx, role, now)
+		if err != nil {
+			return err
+		}
+		if a == nil {
+			continue
+		}
+
+		// Forward unless the delegate wrote or already received it
+		forwardTo := ""
+		if a.ForwardTo != "" && a.ForwardTo != msg.FromID && !containsString(recipients, a.ForwardTo) {
+			forwardTo = a.ForwardTo
+			note := fmt.Sprintf("Forwarded while %s is away.", role)
+			if err := forward(tx, rules, msg, role, forwardTo, note, now); err != nil {
+				return err
+			}
+		}
+
+		if msg.MsgType == "notification" {
+			continue
+		}
+		result, err := tx.Exec(`
+			INSERT OR IGNORE INTO away_replies (role, sender, replied_at) VALUES (?, ?, ?)`,
+			role, msg.FromID, now)
+		if err != nil {
+			return fmt.Errorf("failed to record auto-reply: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			continue
+		}
+		reply := &Message{
+			ID:        NewID(),
+			FromID:    role,
+			Subject:   prefixSubject("RE: ", msg.Subject),
+			Body:      autoReplyBody(a, forwardTo),
+			Priority:  "normal",
+			MsgType:   "notification",
+			ThreadID:  &threadID,
+			ReplyToID: &msg.ID,
+			CreatedAt: now,
+		}
+		if err := insertMessage(tx, reply, []string{msg.FromID}); err != nil {
+			return fmt.Errorf("failed to send auto-reply from %s: %w", role, err)
+		}
+	}
+	return nil
+}
+
+// forward sends a copy of msg from role to forwardTo in msg's thread,
+// keeping its type, priority, deadline, attachments and schedule, and
+// applies rules to it. The body starts with note and the original sender.
+func forward(tx *sql.Tx, rules []Rule, msg *Message, role, forwardTo, note string, now time.Time) error {
+	threadID := msg.ID
+	if msg.ThreadID != nil {
+		threadID = *msg.ThreadID
+	}
+	createdAt := now
+	if msg.DeliverAt != nil {
+		createdAt = *msg.DeliverAt
+	}
+
+	fwd := &Message{
+		ID:          NewID(),
+		FromID:      role,
+		Subject:     prefixSubject("FWD: ", msg.Subject),
+		Body:        fmt.Sprintf("%s From: %s\n\n%s", note, msg.FromID, msg.Body),
+		Priority:    msg.Priority,
+		MsgType:     msg.MsgType,
+		ThreadID:    &threadID,
+		ReplyToID:   &msg.ID,
+		CreatedAt:   createdAt,
+		DeliverAt:   msg.DeliverAt,
+		DueAt:       msg.DueAt,
+		Attachments: msg.Attachments,
+	}
+	if err := deliver(tx, rules, fwd, []string{forwardTo}, now); err != nil {
+		return fmt.Errorf("failed to forward to %s: %w", forwardTo, err)
+	}
+	return nil
+}
+
+// autoReplyBody is the away role's message, or a default one, followed by
+// where the mail went
+func autoReplyBody(a *Away, forwardTo string) string {
+	body := a.Message
+	if body == "" {
+		body = a.Role + " is away"
+		if a.Until != nil {
+			body += " until " + a.Until.Local().Format("2006-01-02 15:04")
+		}
+		body += "."
+	}
+	if forwardTo != "" {
+		body += fmt.Sprintf("\n\n(Auto-reply: your message was forwarded to %s.)", forwardTo)
+	} else {
+		body += "\n\n(Auto-reply)"
+	}
+	return body
+}
+
+// prefixSubject adds prefix to subject unless it already starts with it
+func prefixSubject(prefix, subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), strings.ToLower(prefix)) {
+		return subject
+	}
+	return prefix + subject
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAway(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	until := now.Add(time.Hour)
+	if err := db.SetAway(Away{Role: "dev", ForwardTo: "qa", Message: "On leave", Until: &until, StartedAt: now}); err != nil {
+		t.Fatalf("SetAway failed: %v", err)
+	}
+	if a, err := db.GetAway("dev", now); err != nil || a == nil || a.ForwardTo != "qa" || a.Until == nil {
+		t.Fatalf("expected dev away forwarding to qa, got %+v, %v", a, err)
+	}
+	if a, _ := db.GetAway("dev", until.Add(time.Second)); a != nil {
+		t.Errorf("expected away to lapse after until, got %+v", a)
+	}
+
+	// Mail to dev is forwarded to qa, where qa's rules apply, and pm gets
+	// an auto-reply, all in the original thread
+	db.UseRules([]Rule{{Name: "delegated", To: "qa", Labels: []string{"delegated"}}})
+	err := db.SendMessage(&Message{
+		ID: "msg001", FromID: "pm", Subject: "Review", Body: "Please review",
+		Priority: "high", MsgType: "request", CreatedAt: now,
+	}, []string{"dev"})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	thread, err := db.GetThread("msg001", "pm")
+	if err != nil {
+		t.Fatalf("GetThread failed: %v", err)
+	}
+	if len(thread) != 3 {
+		t.Fatalf("expected original, forward and auto-reply in thread, got %d messages", len(thread))
+	}
+
+	qaInbox, _ := db.GetInbox("qa", StatusUnread)
+	if len(qaInbox) != 1 || qaInbox[0].Subject != "FWD: Review" || qaInbox[0].FromID != "dev" {
+		t.Fatalf("expected forward in qa inbox, got %+v", qaInbox)
+	}
+	if qaInbox[0].MsgType != "request" || qaInbox[0].Priority != "high" {
+		t.Errorf("expected forward to keep type and priority, got %s %s", qaInbox[0].MsgType, qaInbox[0].Priority)
+	}
+	if !qaInbox[0].HasLabel("delegated") {
+		t.Errorf("expected qa's rules to label the forward, got %v", qaInbox[0].Labels)
+	}
+
+	pmInbox, _ := db.GetInbox("pm", StatusUnread)
+	if len(pmInbox) != 1 || pmInbox[0].MsgType != "notification" || pmInbox[0].FromID != "dev" {
+		t.Fatalf("expected auto-reply in pm inbox, got %+v", pmInbox)
+	}
+	if !strings.HasPrefix(pmInbox[0].Body, "On leave") || !strings.Contains(pmInbox[0].Body, "forwarded to qa") {
+		t.Errorf("unexpected auto-reply body: %q", pmInbox[0].Body)
+	}
+
+	// dev still gets the original
+	if devInbox, _ := db.GetInbox("dev", StatusUnread); len(devInbox) != 1 {
+		t.Errorf("expected original in dev inbox, got %d", len(devInbox))
+	}
+
+	// Notifications are forwarded without an auto-reply
+	db.SendMessage(&Message{
+		ID: "msg002", FromID: "pm", Subject: "FYI", Body: "Deployed",
+		Priority: "normal", MsgType: "notification", CreatedAt: now,
+	}, []string{"dev"})
+	if pmInbox, _ := db.GetInbox("pm", StatusUnread); len(pmInbox) != 1 {
+		t.Errorf("expected no auto-reply to a notification, got %d messages", len(pmInbox))
+	}
+	if qaInbox, _ := db.GetInbox("qa", StatusUnread); len(qaInbox) != 2 {
+		t.Errorf("expected notification forwarded to qa, got %d messages", len(qaInbox))
+	}
+
+	// pm gets one auto-reply per away period
+	db.SendMessage(&Message{
+		ID: "msg004", FromID: "pm", Subject: "Ping", Body: "Any news?",
+		Priority: "normal", MsgType: "message", CreatedAt: now,
+	}, []string{"dev"})
+	if pmInbox, _ := db.GetInbox("pm", StatusUnread); len(pmInbox) != 1 {
+		t.Errorf("expected a single auto-reply to pm, got %d messages", len(pmInbox))
+	}
+
+	// Replies between two away roles do not loop
+	db.SetAway(Away{Role: "pm", ForwardTo: "dev", StartedAt: now})
+	db.SendMessage(&Message{
+		ID: "msg003", FromID: "qa", Subject: "Hi", Body: "Hello",
+		Priority: "normal", MsgType: "message", CreatedAt: now,
+	}, []string{"dev"})
+	if pmInbox, _ := db.GetInbox("pm", StatusUnread); len(pmInbox) != 1 {
+		t.Errorf("expected auto-reply to qa not to reach pm, got %d messages", len(pmInbox))
+	}
+
+	if ok, err := db.ClearAway("dev"); err != nil || !ok {
+		t.Fatalf("ClearAway failed: %v, %v", ok, err)
+	}
+	if ok, _ := db.ClearAway("dev"); ok {
+		t.Error("expected second ClearAway to report not away")
+	}
+	away, err := db.GetAllAway(now)
+	if err != nil || len(away) != 1 || away["pm"] == nil || away["pm"].Until != nil {
+		t.Errorf("expected only pm away with no end, got %+v, %v", away, err)
+	}
+}
+
+func TestAwayScheduled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// dev goes away after the message is scheduled but before it arrives
+	db.SendMessage(scheduledMessage("msg001", time.Now().Add(time.Hour)), []string{"dev"})
+	db.SetAway(Away{Role: "dev", ForwardTo: "qa", StartedAt: time.Now()})
+	if qaInbox, _ := db.GetInbox("qa", StatusUnread); len(qaInbox) != 0 {
+		t.Fatalf("expected nothing forwarded before delivery, got %+v", qaInbox)
+	}
+
+	db.Reschedule("msg001", "pm", time.Now().Add(-time.Minute))
+	if n, err := db.DeliverDue(); err != nil || n != 1 {
+		t.Fatalf("DeliverDue = %d, %v; want 1", n, err)
+	}
+	if qaInbox, _ := db.GetInbox("qa", StatusUnread); len(qaInbox) != 1 || qaInbox[0].Subject != "FWD: Handoff" {
+		t.Errorf("expected forward to qa on delivery, got %+v", qaInbox)
+	}
+	if pmInbox, _ := db.GetInbox("pm", StatusUnread); len(pmInbox) != 1 {
+		t.Errorf("expected auto-reply to pm on delivery, got %d messages", len(pmInbox))
+	}
+
+	// Delivered once only
+	if n, _ := db.DeliverDue(); n != 0 {
+		t.Errorf("expected nothing left to deliver, got %d", n)
+	}
+}
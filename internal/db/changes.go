@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// settleDelay is how long after a file event the change sequence is re-read
+const settleDelay = 20 * time.Millisecond
+
+// ChangeSeq returns the mailbox change sequence. It increases every time a
+// message or recipient row is written, by any process.
+func (db *DB) ChangeSeq() (int64, error) {
+	var seq int64
+	err := db.conn.QueryRow(`SELECT seq FROM change_seq WHERE id = 1`).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read change sequence: %w", err)
+	}
+	return seq, nil
+}
+
+// Subscribe returns a channel that receives the new change sequence whenever
+// the mailbox changes. Changes are detected by watching the database directory
+// for writes, so they arrive within milliseconds. The sequence is also polled
+// every fallback interval in case file events are unavailable or missed.
+// Scheduled messages are delivered and snoozed messages woken when they
+// come due, which counts as a change.
+//
+// Bursts of changes are coalesced: a slow reader sees only the latest
+// sequence. The channel is closed when ctx is cancelled.
+func (db *DB) Subscribe(ctx context.Context, fallback time.Duration) (<-chan int64, error) {
+	last, err := db.ChangeSeq()
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing watcher is not fatal; the fallback poll still works
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil && watcher.Add(filepath.Dir(db.path)) != nil {
+		watcher.Close()
+		watcher = nil
+	}
+
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if watcher != nil {
+		events = watcher.Events
+		watchErrors = watcher.Errors
+	}
+
+	ch := make(chan int64, 1)
+	go func() {
+		defer close(ch)
+		if watcher != nil {
+			defer watcher.Close()
+		}
+
+		ticker := time.NewTicker(fallback)
+		defer ticker.Stop()
+
+		// File events can fire before the commit is visible to readers, so
+		// check again shortly after the last one
+		var settle <-chan time.Time
+
+		due := db.nextDueTimer()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-events:
+				settle = time.After(settleDelay)
+			case <-watchErrors:
+			case <-settle:
+				settle = nil
+			case <-ticker.C:
+			case <-due:
+				// The resulting change is picked up below
+				db.releaseDue()
+				due = db.nextDueTimer()
+			}
+
+			seq, err := db.ChangeSeq()
+			if err != nil || seq == last {
+				continue
+			}
+			last = seq
+
+			// A message may have been scheduled or snoozed
+			due = db.nextDueTimer()
+
+			// Replace any undelivered sequence with the latest one
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- seq
+		}
+	}()
+
+	return ch, nil
+}
+
+// releaseDue delivers scheduled messages and wakes snoozed ones whose time
+// has come. Errors only delay notifications, so they are ignored.
+func (db *DB) releaseDue() {
+	_, _ = db.DeliverDue()
+	_, _ = db.WakeSnoozed()
+}
+
+// nextDueTimer returns a channel that fires when the next scheduled message
+// or snooze is due, or nil if there is none
+func (db *DB) nextDueTimer() <-chan time.Time {
+	var next *time.Time
+	for _, due := range []func() (*time.Time, error){db.NextDelivery, db.NextWake} {
+		if t, err := due(); err == nil && t != nil && (next == nil || t.Before(*next)) {
+			next = t
+		}
+	}
+	if next == nil {
+		return nil
+	}
+
+	// Retry overdue messages at a gentle pace rather than spinning
+	d := time.Until(*next)
+	if d < 0 {
+		d = time.Second
+	}
+	return time.After(d)
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChangeSeq(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	before, err := db.ChangeSeq()
+	if err != nil {
+		t.Fatalf("ChangeSeq failed: %v", err)
+	}
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Test",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg, []string{"dev"})
+
+	afterSend, _ := db.ChangeSeq()
+	if afterSend <= before {
+		t.Errorf("expected change sequence to increase on send: %d -> %d", before, afterSend)
+	}
+
+	db.MarkRead("msg001", "dev")
+
+	afterRead, _ := db.ChangeSeq()
+	if afterRead <= afterSend {
+		t.Errorf("expected change sequence to increase on read: %d -> %d", afterSend, afterRead)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := db.Subscribe(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// Write from a separate connection, as another process would
+	other, err := Open(db.path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer other.Close()
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Test",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	if err := other.SendMessage(msg, []string{"dev"}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	// The fallback poll is an hour away, so this must come from file events
+	select {
+	case seq := <-changes:
+		if seq == 0 {
+			t.Error("expected a non-zero change sequence")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change notification")
+	}
+
+	// Cancelling closes the channel
+	cancel()
+	select {
+	case _, ok := <-changes:
+		for ok {
+			_, ok = <-changes
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected channel to close after cancel")
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// unclaimedSQL restricts a query on recipients r to mail no other session
+// holds a live claim on. The current session claims under its own name,
+// or the role's without one. Queries using it start with sessionCTE.
+const unclaimedSQL = `(r.claimed_by IS NULL OR r.acked_at IS NOT NULL
+	OR r.lease_until <= strftime('%Y-%m-%d %H:%M:%S', 'now')
+	OR r.claimed_by = COALESCE(NULLIF((SELECT session FROM cur), ''), r.to_id))`
+
+// ClaimNext atomically claims the oldest unread message for a pool role on
+// behalf of claimer, leasing it until now+lease. Messages whose lease has
+// lapsed without an ack are claimed again. Returns nil if nothing is left
+// to claim.
+func (db *DB) ClaimNext(toID, claimer string, lease time.Duration, now time.Time) (*InboxMessage, error) {
+	// A single UPDATE picks and takes the row, so concurrent claimers
+	// never get the same message
+	var messageID string
+	err := db.conn.QueryRow(`
+		UPDATE recipients SET claimed_by = ?, lease_until = ?
+		WHERE to_id = ? AND message_id = (
+			SELECT r.message_id
+			FROM recipients r
+			JOIN messages m ON m.id = r.message_id
+			WHERE r.to_id = ? AND r.status = 'unread' AND r.acked_at IS NULL
+			  AND (r.claimed_by IS NULL OR r.lease_until <= ?)
+			  AND `+deliveredSQL+` AND `+awakeSQL+`
+			ORDER BY m.created_at ASC, m.rowid ASC
+			LIMIT 1)
+		RETURNING message_id`,
+		claimer, sqlTime(now.Add(lease)), toID, toID, sqlTime(now)).Scan(&messageID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim message: %w", err)
+	}
+
+	return db.GetMessageForRecipient(messageID, toID)
+}
+
+// AckClaim finishes claimer's claim on a message, marking it read so it is
+// never redelivered. Returns false if claimer does not hold the claim.
+func (db *DB) AckClaim(messageID, toID, claimer string) (bool, error) {
+	now := time.Now()
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET acked_at = ?, status = 'read', read_at = COALESCE(read_at, ?)
+		WHERE message_id = ? AND to_id = ? AND claimed_by = ? AND acked_at IS NULL`,
+		now, now, messageID, toID, claimer)
+	if err != nil {
+		return false, fmt.Errorf("failed to ack claim: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// ReleaseClaim gives up claimer's claim so the message can be claimed
+// again right away. Returns false if claimer does not hold the claim.
+func (db *DB) ReleaseClaim(messageID, toID, claimer string) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET claimed_by = NULL, lease_until = NULL
+		WHERE message_id = ? AND to_id = ? AND claimed_by = ? AND acked_at IS NULL`,
+		messageID, toID, claimer)
+	if err != nil {
+		return false, fmt.Errorf("failed to release claim: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// GetClaims returns the messages claimer holds for a pool role and has not
+// acked yet, oldest first, including claims whose lease has lapsed
+func (db *DB) GetClaims(toID, claimer string) ([]InboxMessage, error) {
+	rows, err := db.conn.Query(`
+		SELECT m.id, m.from_id, m.subject, m.body, `+prioritySQL+`, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at,
+		       r.status, r.read_at, r.starred_at, COALESCE(r.request_state, '')
+		FROM messages m
+		JOIN recipients r ON m.id = r.message_id
+		WHERE r.to_id = ? AND r.claimed_by = ? AND r.acked_at IS NULL
+		ORDER BY m.created_at ASC`, toID, claimer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query claims: %w", err)
+	}
+	defer rows.Close()
+
+	claims, messageIDs, err := scanInboxRows(rows, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan claims: %w", err)
+	}
+
+	if err := db.attachRecipients(claims, messageIDs); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClaimLifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	for _, id := range []string{"msg001", "msg002"} {
+		db.SendMessage(&Message{
+			ID:        id,
+			FromID:    "pm",
+			Subject:   "Job " + id,
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: now,
+		}, []string{"dev"})
+		now = now.Add(time.Second)
+	}
+
+	// Oldest first, and a claimed message is not handed out again
+	first, err := db.ClaimNext("dev", "dev#1", time.Minute, time.Now())
+	if err != nil || first == nil || first.ID != "msg001" {
+		t.Fatalf("expected to claim msg001, got %+v, %v", first, err)
+	}
+	second, _ := db.ClaimNext("dev", "dev#2", time.Minute, time.Now())
+	if second == nil || second.ID != "msg002" {
+		t.Fatalf("expected to claim msg002, got %+v", second)
+	}
+	if msg, _ := db.ClaimNext("dev", "dev#3", time.Minute, time.Now()); msg != nil {
+		t.Fatalf("expected nothing left to claim, got %s", msg.ID)
+	}
+
+	// Only the holder can ack or release
+	if ok, _ := db.AckClaim("msg001", "dev", "dev#2"); ok {
+		t.Error("expected ack by another session to be refused")
+	}
+	if ok, err := db.ReleaseClaim("msg002", "dev", "dev#2"); err != nil || !ok {
+		t.Fatalf("ReleaseClaim failed: %v, %v", ok, err)
+	}
+	if msg, _ := db.ClaimNext("dev", "dev#3", time.Minute, time.Now()); msg == nil || msg.ID != "msg002" {
+		t.Fatalf("expected released msg002 to be claimable, got %+v", msg)
+	}
+
+	// An expired lease is redelivered
+	later := time.Now().Add(2 * time.Minute)
+	msg, _ := db.ClaimNext("dev", "dev#2", time.Minute, later)
+	if msg == nil || msg.ID != "msg001" {
+		t.Fatalf("expected lapsed msg001 to be redelivered, got %+v", msg)
+	}
+	if ok, _ := db.AckClaim("msg001", "dev", "dev#1"); ok {
+		t.Error("expected ack after losing the claim to be refused")
+	}
+
+	if ok, err := db.AckClaim("msg001", "dev", "dev#2"); err != nil || !ok {
+		t.Fatalf("AckClaim failed: %v, %v", ok, err)
+	}
+	if msg, _ := db.ClaimNext("dev", "dev#1", time.Minute, later.Add(time.Hour)); msg == nil || msg.ID != "msg002" {
+		t.Fatalf("expected only unacked msg002 to be redelivered, got %+v", msg)
+	}
+
+	if claims, _ := db.GetClaims("dev", "dev#2"); len(claims) != 0 {
+		t.Errorf("expected dev#2 to hold no claims, got %+v", claims)
+	}
+
+	recipients, _ := db.GetRecipientStatus("msg001")
+	if len(recipients) != 1 || recipients[0].Status != StatusRead || recipients[0].AckedAt == nil || recipients[0].ClaimedBy != "dev#2" {
+		t.Errorf("expected msg001 acked by dev#2, got %+v", recipients)
+	}
+}
+
+func TestClaimHidesFromOtherSessions(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.SendMessage(&Message{
+		ID: "msg001", FromID: "pm", Subject: "Job", Body: "Body",
+		Priority: "normal", MsgType: "message", CreatedAt: time.Now().Add(-time.Hour),
+	}, []string{"dev"})
+
+	// dev#1 claims; dev#2 and the role as a whole no longer see it
+	db.UseSession("dev", "dev#1")
+	other, err := Open(db.path)
+	if err != nil {
+		t.Fatalf("failed to open second handle: %v", err)
+	}
+	defer other.Close()
+	other.UseSession("dev", "dev#2")
+
+	if msg, _ := db.ClaimNext("dev", "dev#1", time.Minute, time.Now()); msg == nil {
+		t.Fatal("expected to claim msg001")
+	}
+	if inbox, _ := db.GetInbox("dev", StatusUnread); len(inbox) != 1 {
+		t.Errorf("expected the claimer to still see msg001, got %+v", inbox)
+	}
+	if inbox, _ := other.GetInbox("dev", StatusUnread); len(inbox) != 0 {
+		t.Errorf("expected msg001 hidden from dev#2, got %+v", inbox)
+	}
+	if count, _ := other.CountUnread("dev"); count != 0 {
+		t.Errorf("expected no unread for dev#2, got %d", count)
+	}
+	if pending, _ := other.GetUnnotified("dev"); len(pending) != 0 {
+		t.Errorf("expected no notification for dev#2, got %+v", pending)
+	}
+
+	// A released claim is visible again
+	db.ReleaseClaim("msg001", "dev", "dev#1")
+	if count, _ := other.CountUnread("dev"); count != 1 {
+		t.Errorf("expected msg001 unread for dev#2 after release, got %d", count)
+	}
+}
@@ -1,54 +1,56 @@
 package db
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS messages (
-    id TEXT PRIMARY KEY,
-    from_id TEXT NOT NULL,
-    subject TEXT,
-    body TEXT NOT NULL,
-    priority TEXT DEFAULT 'normal',
-    msg_type TEXT DEFAULT 'message',
-    thread_id TEXT,
-    reply_to_id TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (thread_id) REFERENCES messages(id),
-    FOREIGN KEY (reply_to_id) REFERENCES messages(id)
-);
-
-CREATE TABLE IF NOT EXISTS recipients (
-    message_id TEXT NOT NULL,
-    to_id TEXT NOT NULL,
-    status TEXT DEFAULT 'unread',
-    read_at TIMESTAMP,
-    notified_at TIMESTAMP,
-    PRIMARY KEY (message_id, to_id),
-    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
-);
-
-CREATE INDEX IF NOT EXISTS idx_inbox ON recipients(to_id, status);
-CREATE INDEX IF NOT EXISTS idx_thread ON messages(thread_id);
-CREATE INDEX IF NOT EXISTS idx_messages_created ON messages(created_at DESC);
-`
-
 // DB wraps the SQLite database connection
 type DB struct {
 	conn *sql.DB
 	path string
+
+	// session is the role instance whose read state queries use, set by
+	// UseSession; empty to use the role's own state
+	session     string
+	sessionRole string
+
+	// rules are applied by SendMessage to new mail, set by UseRules
+	rules []Rule
 }
 
-// Open opens the database at the given path
+// Open opens the database at the given path and applies any pending
+// schema migrations
 func Open(path string) (*DB, error) {
+	db, err := OpenNoMigrate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		db.conn.Close()
+		return nil, err
+	}
+
+	// Catch up on scheduled deliveries and snoozes that came due while
+	// nothing was watching
+	db.releaseDue()
+
+	return db, nil
+}
+
+// OpenNoMigrate opens the database at the given path without touching the
+// schema. Use this to inspect migration status before upgrading.
+func OpenNoMigrate(path string) (*DB, error) {
 	// Use connection string pragmas to ensure they apply to all pooled connections
 	// - foreign_keys: enforce referential integrity
 	// - journal_mode=WAL: enable concurrent read/write access
@@ -62,10 +64,9 @@ func Open(path string) (*DB, error) {
 	return &DB{conn: conn, path: path}, nil
 }
 
-// Init initializes the database schema
+// Init initializes the database schema by applying all pending migrations
 func (db *DB) Init() error {
-	_, err := db.conn.Exec(schema)
-	if err != nil {
+	if _, err := db.Migrate(); err != nil {
 		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
 	return nil
@@ -80,15 +81,41 @@ func (db *DB) Close() error {
 
 // Message represents a message in the system
 type Message struct {
-	ID        string
-	FromID    string
-	Subject   string
-	Body      string
-	Priority  string
-	MsgType   string
-	ThreadID  *string
-	ReplyToID *string
-	CreatedAt time.Time
+	ID          string
+	FromID      string
+	Subject     string
+	Body        string
+	Priority    string
+	MsgType     string
+	ThreadID    *string
+	ReplyToID   *string
+	CreatedAt   time.Time
+	DeliverAt   *time.Time // Scheduled delivery time; nil to deliver immediately
+	EditedAt    *time.Time // When the sender last edited the body
+	RecalledAt  *time.Time // When the sender recalled the message
+	DueAt       *time.Time // Deadline for a request; nil if none
+	Attachments []Attachment
+}
+
+// setMessageTimes sets the nullable edit, recall and due times scanned for
+// a message
+func (m *Message) setMessageTimes(editedAt, recalledAt, dueAt sql.NullTime) {
+	if editedAt.Valid {
+		m.EditedAt = &editedAt.Time
+	}
+	if recalledAt.Valid {
+		m.RecalledAt = &recalledAt.Time
+	}
+	if dueAt.Valid {
+		m.DueAt = &dueAt.Time
+	}
+}
+
+// NewID generates a random 16-character hex ID for a new message
+func NewID() string {
+	bytes := make([]byte, 8)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
 }
 
 // Recipient represents a message recipient with read status
@@ -98,18 +125,56 @@ type Recipient struct {
 	Status     string
 	ReadAt     *time.Time
 	NotifiedAt *time.Time
+	ArchivedAt *time.Time
+	TrashedAt  *time.Time
+
+	// RequestState tracks the recipient's progress on a request; empty
+	// for other message types
+	RequestState string
+	EscalatedAt  *time.Time
+
+	// ClaimedBy is the session holding a pool role's copy, empty if
+	// unclaimed; the claim lapses at LeaseUntil unless acked
+	ClaimedBy  string
+	LeaseUntil *time.Time
+	AckedAt    *time.Time
 }
 
+// Recipient statuses
+const (
+	StatusUnread   = "unread"
+	StatusRead     = "read"
+	StatusArchived = "archived"
+	StatusTrashed  = "trashed"
+	StatusRecalled = "recalled" // Withdrawn by the sender before it was read
+)
+
 // InboxMessage combines message data with recipient-specific info
 type InboxMessage struct {
 	Message
 	ToIDs  []string
 	Status string
 	ReadAt *time.Time
+
+	// StarredAt is set while the recipient has the message starred
+	StarredAt *time.Time
+
+	// Labels are the recipient's own labels, set only on inbox queries
+	Labels []string
+
+	// RequestState is the recipient's progress on a request; empty for
+	// other message types and when not loaded for a recipient
+	RequestState string
+
+	// NotifySet is the notify command set a rule picked for the
+	// recipient, set only by GetUnnotified; empty to use the priority's
+	NotifySet string
 }
 
 // scanInboxRows scans rows into InboxMessage slice, handling nullable fields.
-// If includeStatus is true, it expects status and read_at columns in the result.
+// Rows start with the message columns through edited_at, recalled_at and
+// due_at. If includeStatus is true, they are followed by status, read_at,
+// starred_at and request_state.
 func scanInboxRows(rows *sql.Rows, includeStatus bool) ([]InboxMessage, []string, error) {
 	var messages []InboxMessage
 	var messageIDs []string
@@ -117,17 +182,18 @@ func scanInboxRows(rows *sql.Rows, includeStatus bool) ([]InboxMessage, []string
 	for rows.Next() {
 		var msg InboxMessage
 		var threadID, replyToID sql.NullString
-		var readAt sql.NullTime
+		var readAt, starredAt, editedAt, recalledAt, dueAt sql.NullTime
 
 		var err error
 		if includeStatus {
 			err = rows.Scan(
 				&msg.ID, &msg.FromID, &msg.Subject, &msg.Body, &msg.Priority, &msg.MsgType,
-				&threadID, &replyToID, &msg.CreatedAt, &msg.Status, &readAt)
+				&threadID, &replyToID, &msg.CreatedAt, &editedAt, &recalledAt, &dueAt,
+				&msg.Status, &readAt, &starredAt, &msg.RequestState)
 		} else {
 			err = rows.Scan(
 				&msg.ID, &msg.FromID, &msg.Subject, &msg.Body, &msg.Priority, &msg.MsgType,
-				&threadID, &replyToID, &msg.CreatedAt)
+				&threadID, &replyToID, &msg.CreatedAt, &editedAt, &recalledAt, &dueAt)
 		}
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to scan row: %w", err)
@@ -142,6 +208,10 @@ func scanInboxRows(rows *sql.Rows, includeStatus bool) ([]InboxMessage, []string
 		if readAt.Valid {
 			msg.ReadAt = &readAt.Time
 		}
+		if starredAt.Valid {
+			msg.StarredAt = &starredAt.Time
+		}
+		msg.setMessageTimes(editedAt, recalledAt, dueAt)
 
 		messages = append(messages, msg)
 		messageIDs = append(messageIDs, msg.ID)
@@ -154,7 +224,7 @@ func scanInboxRows(rows *sql.Rows, includeStatus bool) ([]InboxMessage, []string
 	return messages, messageIDs, nil
 }
 
-// attachRecipients fetches and assigns recipients to a slice of messages.
+// attachRecipients fetches and assigns recipients and attachments to a slice of messages.
 func (db *DB) attachRecipients(messages []InboxMessage, messageIDs []string) error {
 	if len(messages) == 0 {
 		return nil
@@ -165,13 +235,22 @@ func (db *DB) attachRecipients(messages []InboxMessage, messageIDs []string) err
 		return err
 	}
 
+	attachmentMap, err := db.getAttachmentsForMessages(messageIDs)
+	if err != nil {
+		return err
+	}
+
 	for i := range messages {
 		messages[i].ToIDs = recipientMap[messages[i].ID]
+		messages[i].Attachments = attachmentMap[messages[i].ID]
 	}
 	return nil
 }
 
-// SendMessage creates a new message and adds recipients
+// SendMessage creates a new message and adds recipients. Rules set with
+// UseRules are applied to each recipient's copy, and recipients who are
+// away have it forwarded to their delegate and answered with their
+// auto-reply, all in the same transaction.
 func (db *DB) SendMessage(msg *Message, recipients []string) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
@@ -179,49 +258,128 @@ func (db *DB) SendMessage(msg *Message, recipients []string) error {
 	}
 	defer tx.Rollback()
 
+	now := time.Now()
+	if err := deliver(tx, db.rules, msg, recipients, now); err != nil {
+		return err
+	}
+
+	// Scheduled mail is handled when DeliverDue delivers it, since the
+	// recipient may be back by then
+	if msg.DeliverAt == nil {
+		if err := handleAway(tx, db.rules, msg, recipients, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// deliver stores msg for recipients, closes the request it answers if it
+// is a response, and applies rules to each recipient's copy
+func deliver(tx *sql.Tx, rules []Rule, msg *Message, recipients []string, now time.Time) error {
+	if err := insertMessage(tx, msg, recipients); err != nil {
+		return err
+	}
+
+	// A response closes the sender's copy of the request it answers
+	if msg.MsgType == "response" && msg.ReplyToID != nil {
+		_, err := tx.Exec(`
+			UPDATE recipients SET request_state = 'done'
+			WHERE message_id = ? AND to_id = ? AND request_state IS NOT NULL`,
+			*msg.ReplyToID, msg.FromID)
+		if err != nil {
+			return fmt.Errorf("failed to close request: %w", err)
+		}
+	}
+
+	return applyRules(tx, rules, msg, recipients, now)
+}
+
+// insertMessage stores a message, its search index entry, attachments and
+// recipients
+func insertMessage(tx *sql.Tx, msg *Message, recipients []string) error {
+	var deliverAt, dueAt *string
+	if msg.DeliverAt != nil {
+		t := sqlTime(*msg.DeliverAt)
+		deliverAt = &t
+	}
+	if msg.DueAt != nil {
+		t := sqlTime(*msg.DueAt)
+		dueAt = &t
+	}
+
 	// Insert message
-	_, err = tx.Exec(`
-		INSERT INTO messages (id, from_id, subject, body, priority, msg_type, thread_id, reply_to_id, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		msg.ID, msg.FromID, msg.Subject, msg.Body, msg.Priority, msg.MsgType, msg.ThreadID, msg.ReplyToID, msg.CreatedAt)
+	_, err := tx.Exec(`
+		INSERT INTO messages (id, from_id, subject, body, priority, msg_type, thread_id, reply_to_id, created_at, deliver_at, due_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.FromID, msg.Subject, msg.Body, msg.Priority, msg.MsgType, msg.ThreadID, msg.ReplyToID, msg.CreatedAt, deliverAt, dueAt)
 	if err != nil {
 		return fmt.Errorf("failed to insert message: %w", err)
 	}
 
-	// Insert recipients
-	for _, toID := range recipients {
+	// Index for full-text search
+	_, err = tx.Exec(`
+		INSERT INTO messages_fts (message_id, subject, body)
+		VALUES (?, ?, ?)`,
+		msg.ID, msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("failed to index message: %w", err)
+	}
+
+	// Insert attachments; their blobs must already be stored
+	for _, a := range msg.Attachments {
 		_, err = tx.Exec(`
-			INSERT INTO recipients (message_id, to_id, status)
-			VALUES (?, ?, 'unread')`,
-			msg.ID, toID)
+			INSERT INTO attachments (message_id, name, hash, size)
+			VALUES (?, ?, ?, ?)`,
+			msg.ID, a.Name, a.Hash, a.Size)
 		if err != nil {
-			return fmt.Errorf("failed to insert recipient %s: %w", toID, err)
+			return fmt.Errorf("failed to insert attachment %s: %w", a.Name, err)
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	// Insert recipients; each starts work on a request from open
+	var requestState *string
+	if msg.MsgType == "request" {
+		open := RequestOpen
+		requestState = &open
+	}
+	for _, toID := range recipients {
+		_, err = tx.Exec(`
+			INSERT INTO recipients (message_id, to_id, status, request_state)
+			VALUES (?, ?, 'unread', ?)`,
+			msg.ID, toID, requestState)
+		if err != nil {
+			return fmt.Errorf("failed to insert recipient %s: %w", toID, err)
+		}
 	}
 
 	return nil
 }
 
-// GetInbox retrieves messages for a recipient
-func (db *DB) GetInbox(toID string, includeRead bool) ([]InboxMessage, error) {
-	query := `
-		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
-		       m.thread_id, m.reply_to_id, m.created_at, r.status, r.read_at
-		FROM messages m
-		JOIN recipients r ON m.id = r.message_id
-		WHERE r.to_id = ?`
-
-	if !includeRead {
-		query += ` AND r.status = 'unread'`
+// GetInbox retrieves a recipient's messages whose status is one of
+// statuses, newest first
+func (db *DB) GetInbox(toID string, statuses ...string) ([]InboxMessage, error) {
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("no statuses given")
 	}
 
-	query += ` ORDER BY m.created_at DESC`
+	placeholders, args := inClause(statuses)
+	query := sessionCTE + `
+		SELECT m.id, m.from_id, m.subject, m.body, ` + prioritySQL + `, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at,
+		       ` + sessionStatusSQL + `, r.read_at, r.starred_at, COALESCE(r.request_state, '')
+		FROM messages m
+		JOIN recipients r ON m.id = r.message_id
+		WHERE r.to_id = ? AND ` + deliveredSQL + ` AND ` + awakeSQL + ` AND ` + unclaimedSQL + `
+		  AND ` + sessionStatusSQL + ` IN (` + placeholders + `)
+		ORDER BY m.created_at DESC`
 
-	rows, err := db.conn.Query(query, toID)
+	args = append(append(db.sessionArgs(), toID), args...)
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query inbox: %w", err)
 	}
@@ -236,9 +394,65 @@ func (db *DB) GetInbox(toID string, includeRead bool) ([]InboxMessage, error) {
 		return nil, err
 	}
 
+	labelMap, err := db.getLabelsForMessages(toID, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].Labels = labelMap[messages[i].ID]
+	}
+
 	return messages, nil
 }
 
+// SentMessage is a message sent by an identity, with per-recipient delivery state
+type SentMessage struct {
+	InboxMessage
+	Recipients []Recipient
+}
+
+// GetSent retrieves messages sent by an identity, newest first, excluding
+// scheduled messages that are not yet due. The embedded Status is "unread"
+// while any recipient has not read the message yet, and "read" once every
+// recipient has.
+func (db *DB) GetSent(fromID string) ([]SentMessage, error) {
+	rows, err := db.conn.Query(`
+		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at
+		FROM messages m
+		WHERE m.from_id = ? AND `+deliveredSQL+`
+		ORDER BY m.created_at DESC`, fromID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sent: %w", err)
+	}
+	defer rows.Close()
+
+	messages, messageIDs, err := scanInboxRows(rows, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sent: %w", err)
+	}
+
+	details, err := db.getRecipientDetailsForMessages(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := make([]SentMessage, len(messages))
+	for i, msg := range messages {
+		recipients := details[msg.ID]
+		msg.Status = "read"
+		for _, r := range recipients {
+			msg.ToIDs = append(msg.ToIDs, r.ToID)
+			if r.Status == "unread" {
+				msg.Status = "unread"
+			}
+		}
+		sent[i] = SentMessage{InboxMessage: msg, Recipients: recipients}
+	}
+
+	return sent, nil
+}
+
 // getMessageRecipients returns all recipients for a message
 func (db *DB) getMessageRecipients(messageID string) ([]string, error) {
 	rows, err := db.conn.Query(`SELECT to_id FROM recipients WHERE message_id = ?`, messageID)
@@ -269,17 +483,10 @@ func (db *DB) getRecipientsForMessages(messageIDs []string) (map[string][]string
 		return make(map[string][]string), nil
 	}
 
-	// Build query with placeholders
-	placeholders := make([]string, len(messageIDs))
-	args := make([]interface{}, len(messageIDs))
-	for i, id := range messageIDs {
-		placeholders[i] = "?"
-		args[i] = id
-	}
-
+	placeholders, args := inClause(messageIDs)
 	query := fmt.Sprintf(
 		`SELECT message_id, to_id FROM recipients WHERE message_id IN (%s)`,
-		strings.Join(placeholders, ","),
+		placeholders,
 	)
 
 	rows, err := db.conn.Query(query, args...)
@@ -304,17 +511,117 @@ func (db *DB) getRecipientsForMessages(messageIDs []string) (map[string][]string
 	return result, nil
 }
 
+// getRecipientDetailsForMessages returns full recipient rows (status and
+// timestamps) for multiple messages in a single query, in delivery order
+func (db *DB) getRecipientDetailsForMessages(messageIDs []string) (map[string][]Recipient, error) {
+	if len(messageIDs) == 0 {
+		return make(map[string][]Recipient), nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	query := fmt.Sprintf(`
+		SELECT message_id, to_id, status, read_at, notified_at, archived_at, trashed_at,
+		       COALESCE(request_state, ''), escalated_at,
+		       COALESCE(claimed_by, ''), lease_until, acked_at
+		FROM recipients WHERE message_id IN (%s)
+		ORDER BY rowid`,
+		placeholders,
+	)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recipients: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]Recipient)
+	for rows.Next() {
+		var r Recipient
+		var readAt, notifiedAt, archivedAt, trashedAt, escalatedAt, leaseUntil, ackedAt sql.NullTime
+		err := rows.Scan(&r.MessageID, &r.ToID, &r.Status, &readAt, &notifiedAt, &archivedAt, &trashedAt,
+			&r.RequestState, &escalatedAt, &r.ClaimedBy, &leaseUntil, &ackedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		if readAt.Valid {
+			r.ReadAt = &readAt.Time
+		}
+		if notifiedAt.Valid {
+			r.NotifiedAt = &notifiedAt.Time
+		}
+		if archivedAt.Valid {
+			r.ArchivedAt = &archivedAt.Time
+		}
+		if trashedAt.Valid {
+			r.TrashedAt = &trashedAt.Time
+		}
+		if escalatedAt.Valid {
+			r.EscalatedAt = &escalatedAt.Time
+		}
+		if leaseUntil.Valid {
+			r.LeaseUntil = &leaseUntil.Time
+		}
+		if ackedAt.Valid {
+			r.AckedAt = &ackedAt.Time
+		}
+		result[r.MessageID] = append(result[r.MessageID], r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recipient rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetRecipientStatus returns the delivery state of a message for each of its
+// recipients, in delivery order
+func (db *DB) GetRecipientStatus(messageID string) ([]Recipient, error) {
+	details, err := db.getRecipientDetailsForMessages([]string{messageID})
+	if err != nil {
+		return nil, err
+	}
+	return details[messageID], nil
+}
+
+// GetThreadRecipientStatus returns recipient delivery state for every message
+// in a thread, keyed by message ID
+func (db *DB) GetThreadRecipientStatus(threadID string) (map[string][]Recipient, error) {
+	messages, err := db.GetThread(threadID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make([]string, len(messages))
+	for i, m := range messages {
+		messageIDs[i] = m.ID
+	}
+	return db.getRecipientDetailsForMessages(messageIDs)
+}
+
+// inClause builds "?,?,?" placeholders and matching args for an IN (...) clause
+func inClause(values []string) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		placeholders[i] = "?"
+		args[i] = v
+	}
+	return strings.Join(placeholders, ","), args
+}
+
 // GetMessage retrieves a single message by ID
 func (db *DB) GetMessage(id string) (*InboxMessage, error) {
 	var msg InboxMessage
 	var threadID, replyToID sql.NullString
+	var editedAt, recalledAt, dueAt sql.NullTime
 
 	err := db.conn.QueryRow(`
 		SELECT id, from_id, subject, body, priority, msg_type,
-		       thread_id, reply_to_id, created_at
+		       thread_id, reply_to_id, created_at, edited_at, recalled_at, due_at
 		FROM messages WHERE id = ?`, id).Scan(
 		&msg.ID, &msg.FromID, &msg.Subject, &msg.Body, &msg.Priority, &msg.MsgType,
-		&threadID, &replyToID, &msg.CreatedAt)
+		&threadID, &replyToID, &msg.CreatedAt, &editedAt, &recalledAt, &dueAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -328,6 +635,7 @@ func (db *DB) GetMessage(id string) (*InboxMessage, error) {
 	if replyToID.Valid {
 		msg.ReplyToID = &replyToID.String
 	}
+	msg.setMessageTimes(editedAt, recalledAt, dueAt)
 
 	// Get recipients
 	toIDs, err := db.getMessageRecipients(id)
@@ -336,6 +644,12 @@ func (db *DB) GetMessage(id string) (*InboxMessage, error) {
 	}
 	msg.ToIDs = toIDs
 
+	// Get attachments
+	msg.Attachments, err = db.GetAttachments(id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &msg, nil
 }
 
@@ -343,15 +657,16 @@ func (db *DB) GetMessage(id string) (*InboxMessage, error) {
 func (db *DB) FindMessageByPrefix(prefix string) (*InboxMessage, error) {
 	var msg InboxMessage
 	var threadID, replyToID sql.NullString
+	var editedAt, recalledAt, dueAt sql.NullTime
 
 	// Use LIKE with prefix matching
 	err := db.conn.QueryRow(`
 		SELECT id, from_id, subject, body, priority, msg_type,
-		       thread_id, reply_to_id, created_at
+		       thread_id, reply_to_id, created_at, edited_at, recalled_at, due_at
 		FROM messages WHERE id LIKE ? || '%'
 		LIMIT 1`, prefix).Scan(
 		&msg.ID, &msg.FromID, &msg.Subject, &msg.Body, &msg.Priority, &msg.MsgType,
-		&threadID, &replyToID, &msg.CreatedAt)
+		&threadID, &replyToID, &msg.CreatedAt, &editedAt, &recalledAt, &dueAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -365,6 +680,7 @@ func (db *DB) FindMessageByPrefix(prefix string) (*InboxMessage, error) {
 	if replyToID.Valid {
 		msg.ReplyToID = &replyToID.String
 	}
+	msg.setMessageTimes(editedAt, recalledAt, dueAt)
 
 	// Get recipients
 	toIDs, err := db.getMessageRecipients(msg.ID)
@@ -373,6 +689,12 @@ func (db *DB) FindMessageByPrefix(prefix string) (*InboxMessage, error) {
 	}
 	msg.ToIDs = toIDs
 
+	// Get attachments
+	msg.Attachments, err = db.GetAttachments(msg.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &msg, nil
 }
 
@@ -380,16 +702,19 @@ func (db *DB) FindMessageByPrefix(prefix string) (*InboxMessage, error) {
 func (db *DB) GetMessageForRecipient(id, toID string) (*InboxMessage, error) {
 	var msg InboxMessage
 	var threadID, replyToID sql.NullString
-	var readAt sql.NullTime
+	var readAt, starredAt, editedAt, recalledAt, dueAt sql.NullTime
 
-	err := db.conn.QueryRow(`
-		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
-		       m.thread_id, m.reply_to_id, m.created_at, r.status, r.read_at
+	err := db.conn.QueryRow(sessionCTE+`
+		SELECT m.id, m.from_id, m.subject, m.body, `+prioritySQL+`, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at,
+		       `+sessionStatusSQL+`, r.read_at, r.starred_at, COALESCE(r.request_state, '')
 		FROM messages m
 		JOIN recipients r ON m.id = r.message_id
-		WHERE m.id = ? AND r.to_id = ?`, id, toID).Scan(
+		WHERE m.id = ? AND r.to_id = ? AND `+deliveredSQL+` AND `+awakeSQL,
+		append(db.sessionArgs(), id, toID)...).Scan(
 		&msg.ID, &msg.FromID, &msg.Subject, &msg.Body, &msg.Priority, &msg.MsgType,
-		&threadID, &replyToID, &msg.CreatedAt, &msg.Status, &readAt)
+		&threadID, &replyToID, &msg.CreatedAt, &editedAt, &recalledAt, &dueAt,
+		&msg.Status, &readAt, &starredAt, &msg.RequestState)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -403,9 +728,13 @@ func (db *DB) GetMessageForRecipient(id, toID string) (*InboxMessage, error) {
 	if replyToID.Valid {
 		msg.ReplyToID = &replyToID.String
 	}
+	msg.setMessageTimes(editedAt, recalledAt, dueAt)
 	if readAt.Valid {
 		msg.ReadAt = &readAt.Time
 	}
+	if starredAt.Valid {
+		msg.StarredAt = &starredAt.Time
+	}
 
 	// Get all recipients
 	toIDs, err := db.getMessageRecipients(id)
@@ -414,32 +743,51 @@ func (db *DB) GetMessageForRecipient(id, toID string) (*InboxMessage, error) {
 	}
 	msg.ToIDs = toIDs
 
+	// Get attachments
+	msg.Attachments, err = db.GetAttachments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the recipient's labels
+	msg.Labels, err = db.GetLabels(id, toID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &msg, nil
 }
 
-// MarkRead marks a message as read for a recipient
+// MarkRead marks a message as read for a recipient, and for the current
+// session if one is in use
 func (db *DB) MarkRead(messageID, toID string) error {
+	now := time.Now()
 	_, err := db.conn.Exec(`
 		UPDATE recipients SET status = 'read', read_at = ?
 		WHERE message_id = ? AND to_id = ?`,
-		time.Now(), messageID, toID)
+		now, messageID, toID)
 	if err != nil {
 		return fmt.Errorf("failed to mark as read: %w", err)
 	}
+	if db.session != "" && toID == db.sessionRole {
+		return db.markSession("read_at", []string{messageID}, now)
+	}
 	return nil
 }
 
 // GetUnnotified returns unread messages that haven't been notified yet
 func (db *DB) GetUnnotified(toID string) ([]InboxMessage, error) {
-	query := `
-		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
-		       m.thread_id, m.reply_to_id, m.created_at, r.status, r.read_at
+	query := sessionCTE + `
+		SELECT m.id, m.from_id, m.subject, m.body, ` + prioritySQL + `, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at,
+		       ` + sessionStatusSQL + `, r.read_at, r.starred_at, COALESCE(r.request_state, '')
 		FROM messages m
 		JOIN recipients r ON m.id = r.message_id
-		WHERE r.to_id = ? AND r.status = 'unread' AND r.notified_at IS NULL
+		WHERE r.to_id = ? AND ` + sessionStatusSQL + ` = 'unread' AND NOT ` + sessionNotifiedSQL + `
+		  AND ` + deliveredSQL + ` AND ` + awakeSQL + ` AND ` + unclaimedSQL + `
 		ORDER BY m.created_at DESC`
 
-	rows, err := db.conn.Query(query, toID)
+	rows, err := db.conn.Query(query, append(db.sessionArgs(), toID)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query unnotified: %w", err)
 	}
@@ -453,27 +801,59 @@ func (db *DB) GetUnnotified(toID string) ([]InboxMessage, error) {
 	if err := db.attachRecipients(messages, messageIDs); err != nil {
 		return nil, err
 	}
+	if err := db.attachNotifySets(messages, toID); err != nil {
+		return nil, err
+	}
 
 	return messages, nil
 }
 
-// MarkNotified marks a message as notified for a recipient
+// MarkNotified marks a message as notified for a recipient, and for the
+// current session if one is in use
 func (db *DB) MarkNotified(messageID, toID string) error {
+	now := time.Now()
 	_, err := db.conn.Exec(`
 		UPDATE recipients SET notified_at = ?
 		WHERE message_id = ? AND to_id = ?`,
-		time.Now(), messageID, toID)
+		now, messageID, toID)
 	if err != nil {
 		return fmt.Errorf("failed to mark as notified: %w", err)
 	}
+	if db.session != "" && toID == db.sessionRole {
+		return db.markSession("notified_at", []string{messageID}, now)
+	}
 	return nil
 }
 
-// MarkAllRead marks all messages as read for a recipient
+// MarkAllRead marks all messages as read for a recipient, and for the
+// current session if one is in use
 func (db *DB) MarkAllRead(toID string) (int64, error) {
+	if db.session != "" && toID == db.sessionRole {
+		unread, err := db.GetInbox(toID, StatusUnread)
+		if err != nil {
+			return 0, err
+		}
+		messageIDs := make([]string, len(unread))
+		for i, m := range unread {
+			messageIDs[i] = m.ID
+		}
+		if err := db.markSession("read_at", messageIDs, time.Now()); err != nil {
+			return 0, err
+		}
+		if _, err := db.markRoleRead(toID); err != nil {
+			return 0, err
+		}
+		return int64(len(messageIDs)), nil
+	}
+	return db.markRoleRead(toID)
+}
+
+// markRoleRead marks all of a recipient's unread messages read
+func (db *DB) markRoleRead(toID string) (int64, error) {
 	result, err := db.conn.Exec(`
-		UPDATE recipients SET status = 'read', read_at = ?
-		WHERE to_id = ? AND status = 'unread'`,
+		UPDATE recipients AS r SET status = 'read', read_at = ?
+		WHERE r.to_id = ? AND r.status = 'unread' AND `+awakeSQL+`
+		  AND r.message_id IN (SELECT m.id FROM messages m WHERE `+deliveredSQL+`)`,
 		time.Now(), toID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to mark all as read: %w", err)
@@ -484,46 +864,164 @@ func (db *DB) MarkAllRead(toID string) (int64, error) {
 // Archive marks a message as archived for a recipient
 func (db *DB) Archive(messageID, toID string) error {
 	_, err := db.conn.Exec(`
-		UPDATE recipients SET status = 'archived'
+		UPDATE recipients SET status = 'archived', archived_at = ?
 		WHERE message_id = ? AND to_id = ?`,
-		messageID, toID)
+		time.Now(), messageID, toID)
 	if err != nil {
 		return fmt.Errorf("failed to archive: %w", err)
 	}
 	return nil
 }
 
-// Delete removes a recipient from a message (soft delete for recipient)
-func (db *DB) Delete(messageID, toID string) error {
+// Star pins a message for a recipient. Starring again keeps the original time.
+func (db *DB) Star(messageID, toID string) error {
+	_, err := db.conn.Exec(`
+		UPDATE recipients SET starred_at = ?
+		WHERE message_id = ? AND to_id = ? AND starred_at IS NULL`,
+		time.Now(), messageID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to star: %w", err)
+	}
+	return nil
+}
+
+// Unstar removes a recipient's star from a message
+func (db *DB) Unstar(messageID, toID string) error {
 	_, err := db.conn.Exec(`
-		DELETE FROM recipients WHERE message_id = ? AND to_id = ?`,
+		UPDATE recipients SET starred_at = NULL
+		WHERE message_id = ? AND to_id = ?`,
+		messageID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to unstar: %w", err)
+	}
+	return nil
+}
+
+// SortStarredFirst moves starred messages to the front, keeping the order
+// within starred and unstarred messages
+func SortStarredFirst(messages []InboxMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].StarredAt != nil && messages[j].StarredAt == nil
+	})
+}
+
+// Unarchive moves an archived message back to the recipient's inbox as read.
+// Returns false if the message was not archived.
+func (db *DB) Unarchive(messageID, toID string) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET status = 'read', archived_at = NULL
+		WHERE message_id = ? AND to_id = ? AND status = 'archived'`,
 		messageID, toID)
 	if err != nil {
-		return fmt.Errorf("failed to delete: %w", err)
+		return false, fmt.Errorf("failed to unarchive: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// Trash moves a message to the recipient's trash. The recipient row is kept
+// with status 'trashed' so the message can be undeleted and the sender can
+// still see delivery status.
+func (db *DB) Trash(messageID, toID string) error {
+	_, err := db.conn.Exec(`
+		UPDATE recipients SET status = 'trashed', trashed_at = ?
+		WHERE message_id = ? AND to_id = ?`,
+		time.Now(), messageID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to trash: %w", err)
 	}
 	return nil
 }
 
+// Undelete moves a trashed message back to the recipient's inbox as read.
+// Returns false if the message was not in the trash.
+func (db *DB) Undelete(messageID, toID string) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET status = 'read', trashed_at = NULL
+		WHERE message_id = ? AND to_id = ? AND status = 'trashed'`,
+		messageID, toID)
+	if err != nil {
+		return false, fmt.Errorf("failed to undelete: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// EmptyTrash permanently removes a recipient's trashed messages that were
+// trashed at or before the cutoff. The message itself is kept for its
+// sender and other recipients. Returns the number of messages purged.
+func (db *DB) EmptyTrash(toID string, cutoff time.Time) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// trashed_at is compared in Go since the driver's stored format does not
+	// sort as text across time zones
+	rows, err := tx.Query(`
+		SELECT message_id, trashed_at FROM recipients
+		WHERE to_id = ? AND status = 'trashed'`, toID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query trash: %w", err)
+	}
+	var expired []string
+	for rows.Next() {
+		var messageID string
+		var trashedAt sql.NullTime
+		if err := rows.Scan(&messageID, &trashedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan trash: %w", err)
+		}
+		if !trashedAt.Valid || !trashedAt.Time.After(cutoff) {
+			expired = append(expired, messageID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating trash rows: %w", err)
+	}
+
+	// Labels cascade
+	for _, messageID := range expired {
+		_, err := tx.Exec(`
+			DELETE FROM recipients WHERE message_id = ? AND to_id = ? AND status = 'trashed'`,
+			messageID, toID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(expired), nil
+}
+
 // CountUnread returns the number of unread messages for a recipient
 func (db *DB) CountUnread(toID string) (int, error) {
 	var count int
-	err := db.conn.QueryRow(`
-		SELECT COUNT(*) FROM recipients WHERE to_id = ? AND status = 'unread'`,
-		toID).Scan(&count)
+	err := db.conn.QueryRow(sessionCTE+`
+		SELECT COUNT(*) FROM recipients r
+		JOIN messages m ON m.id = r.message_id
+		WHERE r.to_id = ? AND `+sessionStatusSQL+` = 'unread' AND `+deliveredSQL+` AND `+awakeSQL+` AND `+unclaimedSQL,
+		append(db.sessionArgs(), toID)...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count unread: %w", err)
 	}
 	return count, nil
 }
 
-// GetThread retrieves all messages in a thread
-func (db *DB) GetThread(threadID string) ([]InboxMessage, error) {
+// GetThread retrieves all messages in a thread as viewer sees them. Recalled
+// messages viewer neither sent nor still holds a copy of come back without
+// their body and attachments.
+func (db *DB) GetThread(threadID, viewer string) ([]InboxMessage, error) {
 	// Get the root message and all replies
 	query := `
 		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
-		       m.thread_id, m.reply_to_id, m.created_at
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at
 		FROM messages m
-		WHERE m.id = ? OR m.thread_id = ?
+		WHERE (m.id = ? OR m.thread_id = ?) AND ` + deliveredSQL + `
 		ORDER BY m.created_at ASC`
 
 	rows, err := db.conn.Query(query, threadID, threadID)
@@ -541,18 +1039,24 @@ func (db *DB) GetThread(threadID string) ([]InboxMessage, error) {
 		return nil, err
 	}
 
+	if err := db.withholdRecalled(messages, viewer); err != nil {
+		return nil, err
+	}
+
 	return messages, nil
 }
 
-// GetLatestUnread returns the most recent unread message for a recipient
+// GetLatestUnread returns the unread message at the top of a recipient's inbox:
+// the most recent starred one, otherwise the most recent
 func (db *DB) GetLatestUnread(toID string) (*InboxMessage, error) {
-	messages, err := db.GetInbox(toID, false)
+	messages, err := db.GetInbox(toID, StatusUnread)
 	if err != nil {
 		return nil, err
 	}
 	if len(messages) == 0 {
 		return nil, nil
 	}
+	SortStarredFirst(messages)
 	return &messages[0], nil
 }
 
@@ -74,7 +74,7 @@ func TestConcurrentSendMessages(t *testing.T) {
 	}
 
 	// Verify all messages were persisted
-	inbox, err := db.GetInbox("recipient", false)
+	inbox, err := db.GetInbox("recipient", StatusUnread)
 	if err != nil {
 		t.Fatalf("GetInbox failed: %v", err)
 	}
@@ -169,7 +169,7 @@ func TestReadDuringWrite(t *testing.T) {
 	go func() {
 		defer wg.Done()
 		for i := 0; i < numReads; i++ {
-			if _, err := db.GetInbox("reader", false); err != nil {
+			if _, err := db.GetInbox("reader", StatusUnread); err != nil {
 				readErrors <- err
 			}
 			time.Sleep(time.Millisecond / 2) // Read faster than write
@@ -249,6 +249,84 @@ func TestWatchDuringAgentWrite(t *testing.T) {
 	mu.Unlock()
 }
 
+func TestConcurrentClaims(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "amail-claims-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer db.Close()
+	if err := db.Init(); err != nil {
+		t.Fatalf("failed to init db: %v", err)
+	}
+
+	const numMessages = 5
+	for i := 0; i < numMessages; i++ {
+		db.SendMessage(&Message{
+			ID:        fmt.Sprintf("msg%03d", i),
+			FromID:    "pm",
+			Subject:   fmt.Sprintf("Job %d", i),
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Second),
+		}, []string{"dev"})
+	}
+
+	// Each session claims through its own connection, like separate processes
+	const numSessions = 10
+	var wg sync.WaitGroup
+	claimed := make(chan string, numSessions)
+	errors := make(chan error, numSessions)
+
+	for i := 0; i < numSessions; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			session, err := Open(dbPath)
+			if err != nil {
+				errors <- err
+				return
+			}
+			defer session.Close()
+
+			msg, err := session.ClaimNext("dev", fmt.Sprintf("dev#%d", n), time.Minute, time.Now())
+			if err != nil {
+				errors <- fmt.Errorf("session %d: %w", n, err)
+				return
+			}
+			if msg != nil {
+				claimed <- msg.ID
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(claimed)
+	close(errors)
+
+	for err := range errors {
+		t.Error(err)
+	}
+
+	seen := make(map[string]bool)
+	for id := range claimed {
+		if seen[id] {
+			t.Errorf("message %s claimed twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != numMessages {
+		t.Errorf("expected %d messages claimed, got %d", numMessages, len(seen))
+	}
+}
+
 func TestBusyTimeoutRetry(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "amail-concurrent-*")
 	if err != nil {
@@ -3,6 +3,7 @@ package db
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,6 +47,32 @@ func TestOpenAndInit(t *testing.T) {
 	}
 }
 
+func TestNewID(t *testing.T) {
+	id := NewID()
+
+	// Check length (8 bytes = 16 hex chars)
+	if len(id) != 16 {
+		t.Errorf("NewID() returned %q with length %d, want 16", id, len(id))
+	}
+
+	// Check it's valid hex
+	for _, c := range id {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f')) {
+			t.Errorf("NewID() contains invalid hex character: %c", c)
+		}
+	}
+
+	// Check uniqueness
+	ids := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewID()
+		if ids[id] {
+			t.Errorf("NewID() returned duplicate ID: %s", id)
+		}
+		ids[id] = true
+	}
+}
+
 func TestSendMessage(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -112,7 +139,7 @@ func TestGetInbox(t *testing.T) {
 	db.SendMessage(msg2, []string{"dev"})
 
 	// Get dev's inbox (unread only)
-	inbox, err := db.GetInbox("dev", false)
+	inbox, err := db.GetInbox("dev", StatusUnread)
 	if err != nil {
 		t.Fatalf("GetInbox failed: %v", err)
 	}
@@ -126,7 +153,7 @@ func TestGetInbox(t *testing.T) {
 	}
 
 	// pm's inbox should be empty
-	pmInbox, err := db.GetInbox("pm", false)
+	pmInbox, err := db.GetInbox("pm", StatusUnread)
 	if err != nil {
 		t.Fatalf("GetInbox for pm failed: %v", err)
 	}
@@ -169,7 +196,7 @@ func TestMarkRead(t *testing.T) {
 	}
 
 	// Should still appear in inbox with includeRead=true
-	inbox, _ := db.GetInbox("dev", true)
+	inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
 	if len(inbox) != 1 {
 		t.Errorf("expected 1 message with includeRead, got %d", len(inbox))
 	}
@@ -233,13 +260,102 @@ func TestArchive(t *testing.T) {
 	}
 
 	// Should not appear in unread
-	inbox, _ := db.GetInbox("dev", false)
+	inbox, _ := db.GetInbox("dev", StatusUnread)
 	if len(inbox) != 0 {
 		t.Errorf("expected 0 unread after archive, got %d", len(inbox))
 	}
+
+	// Nor with read messages, only in the archive
+	if inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead); len(inbox) != 0 {
+		t.Errorf("archived message should not be listed with read mail, got %d", len(inbox))
+	}
+	archived, _ := db.GetInbox("dev", StatusArchived)
+	if len(archived) != 1 || archived[0].Status != StatusArchived {
+		t.Fatalf("expected 1 archived message, got %+v", archived)
+	}
+}
+
+func TestUnarchive(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Test",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg, []string{"dev"})
+
+	if ok, _ := db.Unarchive("msg001", "dev"); ok {
+		t.Error("unarchiving a message that isn't archived should report false")
+	}
+
+	db.Archive("msg001", "dev")
+	ok, err := db.Unarchive("msg001", "dev")
+	if err != nil || !ok {
+		t.Fatalf("Unarchive failed: %v (ok=%v)", err, ok)
+	}
+
+	inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
+	if len(inbox) != 1 || inbox[0].Status != StatusRead {
+		t.Fatalf("expected message back in inbox as read, got %+v", inbox)
+	}
+
+	events, _ := db.GetEvents(0, 0)
+	last := events[len(events)-1]
+	if last.Type != "unarchived" {
+		t.Errorf("expected unarchived event, got %q", last.Type)
+	}
+}
+
+func TestStar(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	for i, id := range []string{"msg001", "msg002", "msg003"} {
+		db.SendMessage(&Message{
+			ID:        id,
+			FromID:    "pm",
+			Subject:   "Test",
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}, []string{"dev", "qa"})
+	}
+
+	if err := db.Star("msg001", "dev"); err != nil {
+		t.Fatalf("Star failed: %v", err)
+	}
+
+	inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
+	SortStarredFirst(inbox)
+	if len(inbox) != 3 || inbox[0].ID != "msg001" || inbox[0].StarredAt == nil {
+		t.Fatalf("expected starred msg001 first, got %+v", inbox)
+	}
+	if inbox[1].ID != "msg003" || inbox[2].ID != "msg002" {
+		t.Errorf("unstarred messages should stay newest first, got %s, %s", inbox[1].ID, inbox[2].ID)
+	}
+
+	// Stars are per recipient
+	if msg, _ := db.GetMessageForRecipient("msg001", "qa"); msg.StarredAt != nil {
+		t.Error("qa should not see dev's star")
+	}
+
+	if err := db.Unstar("msg001", "dev"); err != nil {
+		t.Fatalf("Unstar failed: %v", err)
+	}
+	if msg, _ := db.GetMessageForRecipient("msg001", "dev"); msg.StarredAt != nil {
+		t.Error("expected star to be removed")
+	}
 }
 
-func TestDelete(t *testing.T) {
+func TestTrash(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
 
@@ -254,23 +370,99 @@ func TestDelete(t *testing.T) {
 	}
 	db.SendMessage(msg, []string{"dev", "qa"})
 
-	// Delete for dev only
-	err := db.Delete("msg001", "dev")
+	// Trash for dev only
+	err := db.Trash("msg001", "dev")
 	if err != nil {
-		t.Fatalf("Delete failed: %v", err)
+		t.Fatalf("Trash failed: %v", err)
 	}
 
 	// dev should not see it
-	devInbox, _ := db.GetInbox("dev", true)
+	devInbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
 	if len(devInbox) != 0 {
-		t.Errorf("expected 0 messages for dev after delete, got %d", len(devInbox))
+		t.Errorf("expected 0 messages for dev after trash, got %d", len(devInbox))
+	}
+	if trash, _ := db.GetInbox("dev", StatusTrashed); len(trash) != 1 {
+		t.Errorf("expected 1 message in dev's trash, got %d", len(trash))
 	}
 
 	// qa should still see it
-	qaInbox, _ := db.GetInbox("qa", true)
+	qaInbox, _ := db.GetInbox("qa", StatusUnread, StatusRead)
 	if len(qaInbox) != 1 {
 		t.Errorf("expected 1 message for qa, got %d", len(qaInbox))
 	}
+
+	// The sender can still see that dev trashed it
+	recipients, err := db.GetRecipientStatus("msg001")
+	if err != nil {
+		t.Fatalf("GetRecipientStatus failed: %v", err)
+	}
+	if len(recipients) != 2 {
+		t.Fatalf("expected 2 recipients, got %d", len(recipients))
+	}
+	if recipients[0].ToID != "dev" || recipients[0].Status != StatusTrashed || recipients[0].TrashedAt == nil {
+		t.Errorf("expected dev to be trashed with a timestamp, got %+v", recipients[0])
+	}
+}
+
+func TestUndeleteAndEmptyTrash(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, id := range []string{"msg001", "msg002"} {
+		db.SendMessage(&Message{
+			ID:        id,
+			FromID:    "pm",
+			Subject:   "Test",
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: time.Now(),
+		}, []string{"dev", "qa"})
+		db.Trash(id, "dev")
+	}
+	db.UpdateLabels("msg002", "dev", []string{"todo"}, nil)
+
+	if ok, _ := db.Undelete("msg001", "qa"); ok {
+		t.Error("undeleting a message that isn't trashed should report false")
+	}
+	ok, err := db.Undelete("msg001", "dev")
+	if err != nil || !ok {
+		t.Fatalf("Undelete failed: %v (ok=%v)", err, ok)
+	}
+	inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
+	if len(inbox) != 1 || inbox[0].ID != "msg001" || inbox[0].Status != StatusRead {
+		t.Fatalf("expected msg001 back in the inbox as read, got %+v", inbox)
+	}
+
+	// Only messages trashed before the cutoff are purged
+	if n, _ := db.EmptyTrash("dev", time.Now().Add(-time.Hour)); n != 0 {
+		t.Errorf("expected nothing older than an hour, purged %d", n)
+	}
+	n, err := db.EmptyTrash("dev", time.Now())
+	if err != nil || n != 1 {
+		t.Fatalf("EmptyTrash = %d, %v; want 1", n, err)
+	}
+	if trash, _ := db.GetInbox("dev", StatusTrashed); len(trash) != 0 {
+		t.Errorf("expected empty trash, got %d", len(trash))
+	}
+
+	// The message survives for the sender and other recipients
+	recipients, _ := db.GetRecipientStatus("msg002")
+	if len(recipients) != 1 || recipients[0].ToID != "qa" {
+		t.Errorf("expected only qa's copy to remain, got %+v", recipients)
+	}
+
+	events, _ := db.GetEvents(0, 0)
+	var types []string
+	for _, e := range events {
+		if e.Actor == "dev" {
+			types = append(types, e.Type)
+		}
+	}
+	want := []string{"trashed", "trashed", "undeleted", "purged"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("dev events = %v, want %v", types, want)
+	}
 }
 
 func TestThreading(t *testing.T) {
@@ -319,7 +511,7 @@ func TestThreading(t *testing.T) {
 	db.SendMessage(msg3, []string{"dev"})
 
 	// Get thread
-	thread, err := db.GetThread("msg001")
+	thread, err := db.GetThread("msg001", "pm")
 	if err != nil {
 		t.Fatalf("GetThread failed: %v", err)
 	}
@@ -413,6 +605,18 @@ func TestGetLatestUnread(t *testing.T) {
 	if latest.ID != "msg002" {
 		t.Errorf("expected latest to be msg002, got %s", latest.ID)
 	}
+
+	// A starred message is at the top of the inbox, so it comes first
+	if err := db.Star("msg001", "dev"); err != nil {
+		t.Fatalf("Star failed: %v", err)
+	}
+	latest, err = db.GetLatestUnread("dev")
+	if err != nil {
+		t.Fatalf("GetLatestUnread failed: %v", err)
+	}
+	if latest == nil || latest.ID != "msg001" {
+		t.Errorf("expected starred msg001 first, got %v", latest)
+	}
 }
 
 func TestMultipleRecipients(t *testing.T) {
@@ -432,14 +636,14 @@ func TestMultipleRecipients(t *testing.T) {
 
 	// Each recipient should have the message
 	for _, role := range []string{"dev", "qa", "user"} {
-		inbox, _ := db.GetInbox(role, false)
+		inbox, _ := db.GetInbox(role, StatusUnread)
 		if len(inbox) != 1 {
 			t.Errorf("expected 1 message for %s, got %d", role, len(inbox))
 		}
 	}
 
 	// pm (sender) should not have it
-	pmInbox, _ := db.GetInbox("pm", false)
+	pmInbox, _ := db.GetInbox("pm", StatusUnread)
 	if len(pmInbox) != 0 {
 		t.Errorf("expected 0 messages for pm, got %d", len(pmInbox))
 	}
@@ -671,7 +875,7 @@ func TestMarkNotified(t *testing.T) {
 	}
 
 	// Message should still be in inbox (notified doesn't affect read status)
-	inbox, _ := db.GetInbox("dev", false)
+	inbox, _ := db.GetInbox("dev", StatusUnread)
 	if len(inbox) != 1 {
 		t.Errorf("expected message still in inbox after notification, got %d", len(inbox))
 	}
@@ -737,3 +941,150 @@ func TestGetMessageRecipients(t *testing.T) {
 		}
 	})
 }
+
+func TestGetSent(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg1 := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "First",
+		Body:      "Body 1",
+		Priority:  "normal",
+		MsgType:   "request",
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+	db.SendMessage(msg1, []string{"dev", "qa"})
+
+	msg2 := &Message{
+		ID:        "msg002",
+		FromID:    "pm",
+		Subject:   "Second",
+		Body:      "Body 2",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg2, []string{"dev"})
+
+	// Not sent by pm
+	msg3 := &Message{
+		ID:        "msg003",
+		FromID:    "dev",
+		Subject:   "Third",
+		Body:      "Body 3",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg3, []string{"pm"})
+
+	db.MarkRead("msg001", "dev")
+	db.MarkRead("msg002", "dev")
+
+	sent, err := db.GetSent("pm")
+	if err != nil {
+		t.Fatalf("GetSent failed: %v", err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 sent messages, got %d", len(sent))
+	}
+
+	// Newest first
+	if sent[0].ID != "msg002" {
+		t.Errorf("expected most recent message first, got %s", sent[0].ID)
+	}
+
+	// msg002: its only recipient has read it
+	if sent[0].Status != "read" {
+		t.Errorf("expected msg002 status 'read', got '%s'", sent[0].Status)
+	}
+
+	// msg001: dev read it, qa hasn't
+	if sent[1].Status != "unread" {
+		t.Errorf("expected msg001 status 'unread', got '%s'", sent[1].Status)
+	}
+	if len(sent[1].Recipients) != 2 {
+		t.Fatalf("expected 2 recipients for msg001, got %d", len(sent[1].Recipients))
+	}
+	for _, r := range sent[1].Recipients {
+		switch r.ToID {
+		case "dev":
+			if r.Status != "read" || r.ReadAt == nil {
+				t.Errorf("expected dev to have read msg001, got status %s", r.Status)
+			}
+		case "qa":
+			if r.Status != "unread" || r.ReadAt != nil {
+				t.Errorf("expected qa not to have read msg001, got status %s", r.Status)
+			}
+		default:
+			t.Errorf("unexpected recipient %s", r.ToID)
+		}
+	}
+	if len(sent[1].ToIDs) != 2 {
+		t.Errorf("expected ToIDs to list 2 recipients, got %v", sent[1].ToIDs)
+	}
+}
+
+func TestGetRecipientStatus(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Request",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "request",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg, []string{"dev", "qa", "research"})
+
+	db.MarkNotified("msg001", "dev")
+	db.MarkRead("msg001", "dev")
+	db.MarkRead("msg001", "qa")
+	db.Archive("msg001", "qa")
+
+	recipients, err := db.GetRecipientStatus("msg001")
+	if err != nil {
+		t.Fatalf("GetRecipientStatus failed: %v", err)
+	}
+	if len(recipients) != 3 {
+		t.Fatalf("expected 3 recipients, got %d", len(recipients))
+	}
+
+	dev, qa, research := recipients[0], recipients[1], recipients[2]
+	if dev.Status != "read" || dev.ReadAt == nil || dev.NotifiedAt == nil {
+		t.Errorf("expected dev notified and read, got %+v", dev)
+	}
+	if qa.Status != "archived" || qa.ReadAt == nil || qa.ArchivedAt == nil {
+		t.Errorf("expected qa read and archived, got %+v", qa)
+	}
+	if research.Status != "unread" || research.ReadAt != nil || research.NotifiedAt != nil {
+		t.Errorf("expected research untouched, got %+v", research)
+	}
+
+	// Thread rollup covers replies too
+	threadID := "msg001"
+	reply := &Message{
+		ID:        "msg002",
+		FromID:    "dev",
+		Body:      "On it",
+		Priority:  "normal",
+		MsgType:   "response",
+		ThreadID:  &threadID,
+		ReplyToID: &threadID,
+		CreatedAt: time.Now().Add(time.Second),
+	}
+	db.SendMessage(reply, []string{"pm"})
+
+	statuses, err := db.GetThreadRecipientStatus("msg001")
+	if err != nil {
+		t.Fatalf("GetThreadRecipientStatus failed: %v", err)
+	}
+	if len(statuses["msg001"]) != 3 || len(statuses["msg002"]) != 1 {
+		t.Errorf("unexpected thread status: %+v", statuses)
+	}
+}
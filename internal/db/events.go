@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Event is an entry in the append-only mailbox event log
+type Event struct {
+	Seq       int64
+	Type      string // One of EventTypes
+	MessageID string
+	Actor     string // Sender for sent, edited and recalled, claimant for claimed, released and acked, otherwise the recipient
+	CreatedAt time.Time
+
+	// From the message, if it still exists
+	FromID  string
+	Subject string
+}
+
+// EventTypes lists every event type: mailbox changes, then request states
+// and escalation, then pool queue activity
+var EventTypes = []string{
+	"sent", "read", "archived", "unarchived", "trashed", "undeleted", "purged",
+	"snoozed", "notified", "edited", "recalled",
+	"acknowledged", "in-progress", "done", "declined", "open", "escalated",
+	"claimed", "released", "acked",
+}
+
+// GetEvents returns up to limit events with a sequence number greater than
+// afterSeq, oldest first. A limit of 0 returns all of them.
+func (db *DB) GetEvents(afterSeq int64, limit int) ([]Event, error) {
+	query := `
+		SELECT e.seq, e.type, e.message_id, e.actor, e.created_at, m.from_id, m.subject
+		FROM events e
+		LEFT JOIN messages m ON m.id = e.message_id
+		WHERE e.seq > ?
+		ORDER BY e.seq`
+	args := []interface{}{afterSeq}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var fromID, subject sql.NullString
+		err := rows.Scan(&e.Seq, &e.Type, &e.MessageID, &e.Actor, &e.CreatedAt, &fromID, &subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		e.FromID = fromID.String
+		e.Subject = subject.String
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events: %w", err)
+	}
+
+	return events, nil
+}
+
+// LatestEventSeq returns the sequence number of the newest event, or 0
+func (db *DB) LatestEventSeq() (int64, error) {
+	var seq int64
+	err := db.conn.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM events`).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read latest event: %w", err)
+	}
+	return seq, nil
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestEventsRecorded(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Test",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg, []string{"dev", "qa"})
+
+	db.MarkNotified("msg001", "dev")
+	db.MarkRead("msg001", "dev")
+	db.MarkRead("msg001", "dev") // Already read: no new event
+	db.Archive("msg001", "dev")
+	db.Trash("msg001", "qa")
+
+	events, err := db.GetEvents(0, 0)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+
+	want := []struct{ typ, actor string }{
+		{"sent", "pm"},
+		{"notified", "dev"},
+		{"read", "dev"},
+		{"archived", "dev"},
+		{"trashed", "qa"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Type != w.typ || e.Actor != w.actor || e.MessageID != "msg001" {
+			t.Errorf("event %d: expected %s by %s, got %s by %s", i, w.typ, w.actor, e.Type, e.Actor)
+		}
+		if e.Subject != "Test" || e.FromID != "pm" {
+			t.Errorf("event %d: expected message details, got %+v", i, e)
+		}
+		if i > 0 && e.Seq <= events[i-1].Seq {
+			t.Errorf("event %d: sequence not increasing", i)
+		}
+	}
+}
+
+func TestGetEventsCursor(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, id := range []string{"msg001", "msg002", "msg003"} {
+		db.SendMessage(&Message{
+			ID: id, FromID: "pm", Body: "Body", Priority: "normal",
+			MsgType: "message", CreatedAt: time.Now(),
+		}, []string{"dev"})
+	}
+
+	first, err := db.GetEvents(0, 2)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(first) != 2 {
+		t.Fatalf("expected 2 events with limit, got %d", len(first))
+	}
+
+	rest, err := db.GetEvents(first[1].Seq, 0)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(rest) != 1 || rest[0].MessageID != "msg003" {
+		t.Errorf("expected to resume at msg003, got %+v", rest)
+	}
+}
+
+func TestEventTypesCoverTriggers(t *testing.T) {
+	known := make(map[string]bool)
+	for _, typ := range EventTypes {
+		known[typ] = true
+	}
+
+	// Every literal type a trigger inserts is listed
+	literal := regexp.MustCompile(`(?:VALUES \(|THEN )'([a-z-]+)'`)
+	for _, m := range migrations {
+		for _, match := range literal.FindAllStringSubmatch(m.SQL, -1) {
+			if !known[match[1]] {
+				t.Errorf("migration %d inserts event type %q missing from EventTypes", m.Version, match[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LabelCount is the number of a recipient's messages carrying a label
+type LabelCount struct {
+	Label string
+	Count int
+}
+
+// HasLabel reports whether the recipient has labelled the message
+func (m *InboxMessage) HasLabel(label string) bool {
+	for _, l := range m.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateLabels adds and removes a recipient's labels on a message in one
+// transaction. Adding a label that is already set is a no-op.
+func (db *DB) UpdateLabels(messageID, toID string, add, remove []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, label := range add {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO labels (message_id, to_id, label) VALUES (?, ?, ?)`,
+			messageID, toID, label)
+		if err != nil {
+			return fmt.Errorf("failed to add label: %w", err)
+		}
+	}
+
+	for _, label := range remove {
+		_, err := tx.Exec(`
+			DELETE FROM labels WHERE message_id = ? AND to_id = ? AND label = ?`,
+			messageID, toID, label)
+		if err != nil {
+			return fmt.Errorf("failed to remove label: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetLabels returns a recipient's labels on a message, sorted by name
+func (db *DB) GetLabels(messageID, toID string) ([]string, error) {
+	labelMap, err := db.getLabelsForMessages(toID, []string{messageID})
+	if err != nil {
+		return nil, err
+	}
+	return labelMap[messageID], nil
+}
+
+// CountLabels returns how many of a recipient's messages outside the trash
+// (and not recalled) carry each label, most used first
+func (db *DB) CountLabels(toID string) ([]LabelCount, error) {
+	rows, err := db.conn.Query(`
+		SELECT l.label, COUNT(*)
+		FROM labels l
+		JOIN recipients r ON r.message_id = l.message_id AND r.to_id = l.to_id
+		WHERE l.to_id = ? AND r.status NOT IN ('trashed', 'recalled')
+		GROUP BY l.label`, toID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count labels: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []LabelCount
+	for rows.Next() {
+		var c LabelCount
+		if err := rows.Scan(&c.Label, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan label count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating label rows: %w", err)
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Label < counts[j].Label
+	})
+	return counts, nil
+}
+
+// getLabelsForMessages fetches a recipient's labels for multiple messages in
+// one query
+func (db *DB) getLabelsForMessages(toID string, messageIDs []string) (map[string][]string, error) {
+	if len(messageIDs) == 0 {
+		return make(map[string][]string), nil
+	}
+
+	placeholders, args := inClause(messageIDs)
+	query := fmt.Sprintf(`
+		SELECT message_id, label FROM labels
+		WHERE to_id = ? AND message_id IN (%s)
+		ORDER BY message_id, label`,
+		placeholders,
+	)
+
+	rows, err := db.conn.Query(query, append([]interface{}{toID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var messageID, label string
+		if err := rows.Scan(&messageID, &label); err != nil {
+			return nil, fmt.Errorf("failed to scan label: %w", err)
+		}
+		result[messageID] = append(result[messageID], label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating label rows: %w", err)
+	}
+
+	return result, nil
+}
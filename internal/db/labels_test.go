@@ -0,0 +1,76 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLabels(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, id := range []string{"msg001", "msg002"} {
+		db.SendMessage(&Message{
+			ID:        id,
+			FromID:    "pm",
+			Subject:   "Task " + id,
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: time.Now(),
+		}, []string{"dev", "qa"})
+	}
+
+	if err := db.UpdateLabels("msg001", "dev", []string{"blocked", "todo"}, nil); err != nil {
+		t.Fatalf("UpdateLabels failed: %v", err)
+	}
+	db.UpdateLabels("msg002", "dev", []string{"todo"}, nil)
+
+	// Adding twice and removing in the same call
+	if err := db.UpdateLabels("msg001", "dev", []string{"blocked"}, []string{"todo"}); err != nil {
+		t.Fatalf("UpdateLabels failed: %v", err)
+	}
+
+	labels, err := db.GetLabels("msg001", "dev")
+	if err != nil {
+		t.Fatalf("GetLabels failed: %v", err)
+	}
+	if len(labels) != 1 || labels[0] != "blocked" {
+		t.Errorf("expected [blocked], got %v", labels)
+	}
+
+	// Labels are per recipient
+	if labels, _ := db.GetLabels("msg001", "qa"); len(labels) != 0 {
+		t.Errorf("qa should have no labels, got %v", labels)
+	}
+
+	inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
+	for _, m := range inbox {
+		if m.ID == "msg001" && !m.HasLabel("blocked") {
+			t.Errorf("expected msg001 labelled blocked in inbox, got %v", m.Labels)
+		}
+		if m.ID == "msg002" && (len(m.Labels) != 1 || !m.HasLabel("todo")) {
+			t.Errorf("expected msg002 labelled todo in inbox, got %v", m.Labels)
+		}
+	}
+
+	msg, _ := db.GetMessageForRecipient("msg002", "dev")
+	if msg == nil || !msg.HasLabel("todo") {
+		t.Errorf("expected read view to carry labels, got %+v", msg)
+	}
+
+	counts, err := db.CountLabels("dev")
+	if err != nil {
+		t.Fatalf("CountLabels failed: %v", err)
+	}
+	if len(counts) != 2 || counts[0].Count != 1 || counts[1].Count != 1 {
+		t.Errorf("expected one blocked and one todo, got %+v", counts)
+	}
+
+	// Trashed messages drop out of the counts
+	db.Trash("msg002", "dev")
+	counts, _ = db.CountLabels("dev")
+	if len(counts) != 1 || counts[0].Label != "blocked" {
+		t.Errorf("expected only blocked after delete, got %+v", counts)
+	}
+}
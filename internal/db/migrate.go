@@ -0,0 +1,685 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single numbered schema change. Migrations are applied in
+// order, each inside its own transaction, and recorded in schema_version.
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrations is the ordered list of schema changes. Never edit or reorder an
+// entry once it has shipped; append a new migration instead.
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		// Uses IF NOT EXISTS so databases created before versioning was
+		// introduced adopt the baseline without error.
+		SQL: `
+CREATE TABLE IF NOT EXISTS messages (
+    id TEXT PRIMARY KEY,
+    from_id TEXT NOT NULL,
+    subject TEXT,
+    body TEXT NOT NULL,
+    priority TEXT DEFAULT 'normal',
+    msg_type TEXT DEFAULT 'message',
+    thread_id TEXT,
+    reply_to_id TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (thread_id) REFERENCES messages(id),
+    FOREIGN KEY (reply_to_id) REFERENCES messages(id)
+);
+
+CREATE TABLE IF NOT EXISTS recipients (
+    message_id TEXT NOT NULL,
+    to_id TEXT NOT NULL,
+    status TEXT DEFAULT 'unread',
+    read_at TIMESTAMP,
+    notified_at TIMESTAMP,
+    PRIMARY KEY (message_id, to_id),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox ON recipients(to_id, status);
+CREATE INDEX IF NOT EXISTS idx_thread ON messages(thread_id);
+CREATE INDEX IF NOT EXISTS idx_messages_created ON messages(created_at DESC);
+`,
+	},
+	{
+		Version: 2,
+		Name:    "full_text_search",
+		SQL: `
+CREATE VIRTUAL TABLE messages_fts USING fts5(
+    message_id UNINDEXED,
+    subject,
+    body
+);
+
+INSERT INTO messages_fts (message_id, subject, body)
+SELECT id, COALESCE(subject, ''), body FROM messages;
+`,
+	},
+	{
+		Version: 3,
+		Name:    "sent_index",
+		SQL: `
+CREATE INDEX IF NOT EXISTS idx_messages_from ON messages(from_id, created_at DESC);
+`,
+	},
+	{
+		Version: 4,
+		Name:    "recipient_timestamps",
+		// Deleting now keeps the recipient row with status 'deleted' so the
+		// sender can still see what happened to the message.
+		SQL: `
+ALTER TABLE recipients ADD COLUMN archived_at TIMESTAMP;
+ALTER TABLE recipients ADD COLUMN deleted_at TIMESTAMP;
+`,
+	},
+	{
+		Version: 5,
+		Name:    "change_seq",
+		// A single counter bumped by triggers on every write, so subscribers
+		// can tell whether anything changed with one cheap read.
+		SQL: `
+CREATE TABLE change_seq (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    seq INTEGER NOT NULL
+);
+INSERT INTO change_seq (id, seq) VALUES (1, 0);
+
+CREATE TRIGGER messages_change_insert AFTER INSERT ON messages
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+
+CREATE TRIGGER recipients_change_insert AFTER INSERT ON recipients
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+
+CREATE TRIGGER recipients_change_update AFTER UPDATE ON recipients
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+
+CREATE TRIGGER recipients_change_delete AFTER DELETE ON recipients
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+`,
+	},
+	{
+		Version: 6,
+		Name:    "events",
+		// Events are recorded by triggers so every writer is captured
+		SQL: `
+CREATE TABLE events (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,         -- sent, read, archived, deleted, notified
+    message_id TEXT NOT NULL,
+    actor TEXT NOT NULL,        -- sender for 'sent', otherwise the recipient
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TRIGGER messages_event_sent AFTER INSERT ON messages
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('sent', NEW.id, NEW.from_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER recipients_event_status AFTER UPDATE OF status ON recipients
+WHEN NEW.status != OLD.status AND NEW.status IN ('read', 'archived', 'deleted')
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES (NEW.status, NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER recipients_event_notified AFTER UPDATE OF notified_at ON recipients
+WHEN OLD.notified_at IS NULL AND NEW.notified_at IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('notified', NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 7,
+		Name:    "attachments",
+		// Contents live in .amail/blobs/, addressed by their SHA-256 hash
+		SQL: `
+CREATE TABLE attachments (
+    message_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    PRIMARY KEY (message_id, name),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_attachments_hash ON attachments(hash);
+`,
+	},
+	{
+		Version: 8,
+		Name:    "scheduled_delivery",
+		// deliver_at is stored in UTC in strftime's format so it compares
+		// correctly as text. It is cleared once the message is delivered,
+		// which records the 'sent' event instead of the insert.
+		SQL: `
+ALTER TABLE messages ADD COLUMN deliver_at TIMESTAMP;
+
+CREATE INDEX idx_messages_deliver_at ON messages(deliver_at) WHERE deliver_at IS NOT NULL;
+
+DROP TRIGGER messages_event_sent;
+
+CREATE TRIGGER messages_event_sent AFTER INSERT ON messages
+WHEN NEW.deliver_at IS NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('sent', NEW.id, NEW.from_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER messages_event_delivered AFTER UPDATE OF deliver_at ON messages
+WHEN OLD.deliver_at IS NOT NULL AND NEW.deliver_at IS NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('sent', NEW.id, NEW.from_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER messages_change_schedule AFTER UPDATE OF deliver_at ON messages
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+`,
+	},
+	{
+		Version: 9,
+		Name:    "snooze",
+		// snoozed_until uses the same UTC text format as deliver_at and is
+		// cleared when the snooze expires
+		SQL: `
+ALTER TABLE recipients ADD COLUMN snoozed_until TIMESTAMP;
+
+CREATE INDEX idx_recipients_snoozed ON recipients(snoozed_until) WHERE snoozed_until IS NOT NULL;
+
+CREATE TRIGGER recipients_event_snoozed AFTER UPDATE OF snoozed_until ON recipients
+WHEN NEW.snoozed_until IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('snoozed', NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 10,
+		Name:    "labels",
+		// Labels belong to a recipient's copy of a message, like status
+		SQL: `
+CREATE TABLE labels (
+    message_id TEXT NOT NULL,
+    to_id TEXT NOT NULL,
+    label TEXT NOT NULL,
+    PRIMARY KEY (message_id, to_id, label),
+    FOREIGN KEY (message_id, to_id) REFERENCES recipients(message_id, to_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_labels_recipient ON labels(to_id, label);
+
+CREATE TRIGGER labels_change_insert AFTER INSERT ON labels
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+
+CREATE TRIGGER labels_change_delete AFTER DELETE ON labels
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+`,
+	},
+	{
+		Version: 11,
+		Name:    "starred",
+		SQL: `
+ALTER TABLE recipients ADD COLUMN starred_at TIMESTAMP;
+
+CREATE INDEX idx_recipients_starred ON recipients(to_id) WHERE starred_at IS NOT NULL;
+`,
+	},
+	{
+		Version: 12,
+		Name:    "unarchive",
+		// Moving an archived message back to read is an unarchive, not a read
+		SQL: `
+DROP TRIGGER recipients_event_status;
+
+CREATE TRIGGER recipients_event_status AFTER UPDATE OF status ON recipients
+WHEN NEW.status != OLD.status AND NEW.status IN ('read', 'archived', 'deleted')
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES (CASE WHEN OLD.status = 'archived' AND NEW.status = 'read' THEN 'unarchived' ELSE NEW.status END,
+            NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 13,
+		Name:    "trash",
+		// Deleted mail goes to the trash, where it can be undeleted until the
+		// recipient's row is purged. Existing deletions move to the trash
+		// without recording new events.
+		SQL: `
+ALTER TABLE recipients RENAME COLUMN deleted_at TO trashed_at;
+
+DROP TRIGGER recipients_event_status;
+
+UPDATE recipients SET status = 'trashed' WHERE status = 'deleted';
+
+CREATE TRIGGER recipients_event_status AFTER UPDATE OF status ON recipients
+WHEN NEW.status != OLD.status AND NEW.status IN ('read', 'archived', 'trashed')
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES (CASE
+                WHEN OLD.status = 'archived' AND NEW.status = 'read' THEN 'unarchived'
+                WHEN OLD.status = 'trashed' THEN 'undeleted'
+                ELSE NEW.status
+            END,
+            NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER recipients_event_purged AFTER DELETE ON recipients
+WHEN OLD.status = 'trashed'
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('purged', OLD.message_id, OLD.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 14,
+		Name:    "revisions",
+		// messages.body always holds the latest revision; revisions keeps the
+		// bodies it replaced. Recalling withdraws unread copies by moving
+		// their recipients to 'recalled'.
+		SQL: `
+ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP;
+ALTER TABLE messages ADD COLUMN recalled_at TIMESTAMP;
+
+CREATE TABLE revisions (
+    message_id TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (message_id, revision),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER messages_event_edited AFTER UPDATE OF edited_at ON messages
+WHEN NEW.edited_at IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('edited', NEW.id, NEW.from_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER messages_event_recalled AFTER UPDATE OF recalled_at ON messages
+WHEN OLD.recalled_at IS NULL AND NEW.recalled_at IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('recalled', NEW.id, NEW.from_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER messages_change_revise AFTER UPDATE OF edited_at, recalled_at ON messages
+BEGIN UPDATE change_seq SET seq = seq + 1 WHERE id = 1; END;
+`,
+	},
+	{
+		Version: 15,
+		Name:    "request_state",
+		// Each recipient of a request tracks its own progress on it.
+		// Existing requests start open, or done if the recipient already
+		// sent a response.
+		SQL: `
+ALTER TABLE recipients ADD COLUMN request_state TEXT;
+
+UPDATE recipients SET request_state = 'open'
+WHERE message_id IN (SELECT id FROM messages WHERE msg_type = 'request');
+
+UPDATE recipients AS r SET request_state = 'done'
+WHERE r.request_state = 'open' AND EXISTS (
+    SELECT 1 FROM messages reply
+    WHERE reply.reply_to_id = r.message_id AND reply.from_id = r.to_id
+      AND reply.msg_type = 'response');
+
+CREATE INDEX idx_recipients_request ON recipients(to_id, request_state) WHERE request_state IS NOT NULL;
+
+CREATE TRIGGER recipients_event_request AFTER UPDATE OF request_state ON recipients
+WHEN OLD.request_state IS NOT NULL AND NEW.request_state != OLD.request_state
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES (NEW.request_state, NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 16,
+		Name:    "due_dates",
+		// due_at uses the same UTC text format as deliver_at. escalated_at
+		// is set once a recipient's overdue request has been escalated.
+		SQL: `
+ALTER TABLE messages ADD COLUMN due_at TIMESTAMP;
+ALTER TABLE recipients ADD COLUMN escalated_at TIMESTAMP;
+
+CREATE INDEX idx_messages_due_at ON messages(due_at) WHERE due_at IS NOT NULL;
+
+CREATE TRIGGER recipients_event_escalated AFTER UPDATE OF escalated_at ON recipients
+WHEN OLD.escalated_at IS NULL AND NEW.escalated_at IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('escalated', NEW.message_id, NEW.to_id, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 17,
+		Name:    "claims",
+		// A pool role's copy of a message is claimed by one session at a
+		// time. lease_until uses the same UTC text format as deliver_at so
+		// expired claims can be found in SQL; acked_at marks it finished.
+		SQL: `
+ALTER TABLE recipients ADD COLUMN claimed_by TEXT;
+ALTER TABLE recipients ADD COLUMN lease_until TIMESTAMP;
+ALTER TABLE recipients ADD COLUMN acked_at TIMESTAMP;
+
+CREATE TRIGGER recipients_event_claimed AFTER UPDATE OF claimed_by ON recipients
+WHEN NEW.claimed_by IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('claimed', NEW.message_id, NEW.claimed_by, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER recipients_event_released AFTER UPDATE OF claimed_by ON recipients
+WHEN OLD.claimed_by IS NOT NULL AND NEW.claimed_by IS NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('released', NEW.message_id, OLD.claimed_by, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+
+CREATE TRIGGER recipients_event_acked AFTER UPDATE OF acked_at ON recipients
+WHEN OLD.acked_at IS NULL AND NEW.acked_at IS NOT NULL
+BEGIN
+    INSERT INTO events (type, message_id, actor, created_at)
+    VALUES ('acked', NEW.message_id, NEW.claimed_by, strftime('%Y-%m-%d %H:%M:%f', 'now'));
+END;
+`,
+	},
+	{
+		Version: 18,
+		Name:    "sessions",
+		// Named instances of a role (dev#1) share its mail but keep their
+		// own read and notified state in session_reads. Mail delivered
+		// before a session started keeps the role's state.
+		SQL: `
+CREATE TABLE sessions (
+    id TEXT PRIMARY KEY,
+    role TEXT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    last_seen TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_sessions_role ON sessions(role, last_seen DESC);
+
+CREATE TABLE session_reads (
+    message_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    read_at TIMESTAMP,
+    notified_at TIMESTAMP,
+    PRIMARY KEY (message_id, session_id),
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+`,
+	},
+	{
+		Version: 19,
+		Name:    "presence",
+		// One row per identity (role or role instance), refreshed by every
+		// invocation and by a running watch
+		SQL: `
+CREATE TABLE presence (
+    name TEXT PRIMARY KEY,
+    role TEXT NOT NULL,
+    host TEXT NOT NULL DEFAULT '',
+    pid INTEGER NOT NULL DEFAULT 0,
+    tmux TEXT NOT NULL DEFAULT '',
+    last_seen TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_presence_role ON presence(role, last_seen DESC);
+`,
+	},
+	{
+		Version: 20,
+		Name:    "away",
+		// Out-of-office state per role; until is stored like deliver_at
+		SQL: `
+CREATE TABLE away (
+    role TEXT PRIMARY KEY,
+    forward_to TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    until TIMESTAMP,
+    started_at TIMESTAMP NOT NULL
+);
+`,
+	},
+	{
+		Version: 21,
+		Name:    "rules",
+		// The notify command set a rule picked for a recipient's copy;
+		// NULL to use the message's priority
+		SQL: `
+ALTER TABLE recipients ADD COLUMN notify_set TEXT;
+`,
+	},
+	{
+		Version: 22,
+		Name:    "away_replies",
+		// Senders a role away has auto-replied to, so each gets one reply
+		// per away period
+		SQL: `
+CREATE TABLE away_replies (
+    role TEXT NOT NULL,
+    sender TEXT NOT NULL,
+    replied_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (role, sender)
+);
+`,
+	},
+	{
+		Version: 23,
+		Name:    "rule_priority",
+		// The priority a rule set for a recipient's copy; NULL to use the
+		// message's own
+		SQL: `
+ALTER TABLE recipients ADD COLUMN priority_set TEXT;
+`,
+	},
+}
+
+const schemaVersionTable = `
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+);
+`
+
+// MigrationStatus describes whether a known migration has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// LatestSchemaVersion returns the schema version this build of amail expects
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the highest migration version applied to the database,
+// or 0 if the database has never been migrated
+func (db *DB) SchemaVersion() (int, error) {
+	return schemaVersion(context.Background(), db.conn)
+}
+
+// queryRower is satisfied by *sql.DB, *sql.Conn and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func schemaVersion(ctx context.Context, q queryRower) (int, error) {
+	var exists int
+	err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name = 'schema_version'`).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check schema version: %w", err)
+	}
+	if exists == 0 {
+		return 0, nil
+	}
+
+	var version int
+	err = q.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// Migrations lists every known migration and whether it has been applied
+func (db *DB) Migrations() ([]MigrationStatus, error) {
+	applied := make(map[int]time.Time)
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if current > 0 {
+		rows, err := db.conn.Query(`SELECT version, applied_at FROM schema_version`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query schema version: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var version int
+			var appliedAt time.Time
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan schema version: %w", err)
+			}
+			applied[version] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating schema version rows: %w", err)
+		}
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		statuses[i] = MigrationStatus{Version: m.Version, Name: m.Name}
+		if at, ok := applied[m.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Migrate applies all pending migrations and returns how many were applied.
+// It is safe to call concurrently from several processes: each migration runs
+// in a BEGIN IMMEDIATE transaction and re-checks the version under the lock.
+func (db *DB) Migrate() (int, error) {
+	ctx := context.Background()
+	latest := LatestSchemaVersion()
+
+	// Fast path: nothing to do, and no write lock taken
+	current, err := schemaVersion(ctx, db.conn)
+	if err != nil {
+		return 0, err
+	}
+	if current > latest {
+		return 0, fmt.Errorf("database schema version %d is newer than this amail supports (%d); upgrade amail", current, latest)
+	}
+	if current == latest {
+		return 0, nil
+	}
+
+	// Pin a single connection so BEGIN/COMMIT apply to the same session
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	applied := 0
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		ok, err := applyMigration(ctx, conn, m)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+		current = m.Version
+	}
+
+	return applied, nil
+}
+
+// applyMigration runs a single migration in its own transaction. It returns
+// false if another process applied the migration first.
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) (bool, error) {
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return false, fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+	}
+	rollback := func() {
+		_, _ = conn.ExecContext(ctx, "ROLLBACK")
+	}
+
+	if _, err := conn.ExecContext(ctx, schemaVersionTable); err != nil {
+		rollback()
+		return false, fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := schemaVersion(ctx, conn)
+	if err != nil {
+		rollback()
+		return false, err
+	}
+	if current >= m.Version {
+		rollback()
+		return false, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, m.SQL); err != nil {
+		rollback()
+		return false, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, time.Now())
+	if err != nil {
+		rollback()
+		return false, fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		rollback()
+		return false, fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("expected schema version %d, got %d", LatestSchemaVersion(), version)
+	}
+
+	statuses, err := db.Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if len(statuses) != len(migrations) {
+		t.Fatalf("expected %d migrations, got %d", len(migrations), len(statuses))
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.AppliedAt == nil {
+			t.Errorf("expected migration %d (%s) to be applied", s.Version, s.Name)
+		}
+	}
+
+	// Running again is a no-op
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if applied != 0 {
+		t.Errorf("expected 0 migrations applied on second run, got %d", applied)
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "amail-migrate-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	// Simulate a mailbox created before schema versioning existed
+	raw, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open raw db: %v", err)
+	}
+	if _, err := raw.Exec(migrations[0].SQL); err != nil {
+		raw.Close()
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+	_, err = raw.Exec(`INSERT INTO messages (id, from_id, subject, body, created_at) VALUES (?, ?, ?, ?, ?)`,
+		"legacy1", "pm", "Old", "Old body", time.Now())
+	if err == nil {
+		_, err = raw.Exec(`INSERT INTO recipients (message_id, to_id, status) VALUES ('legacy1', 'dev', 'unread')`)
+	}
+	raw.Close()
+	if err != nil {
+		t.Fatalf("failed to insert legacy data: %v", err)
+	}
+
+	// Before upgrade the database reports version 0
+	unmigrated, err := OpenNoMigrate(dbPath)
+	if err != nil {
+		t.Fatalf("OpenNoMigrate failed: %v", err)
+	}
+	version, err := unmigrated.SchemaVersion()
+	unmigrated.Close()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != 0 {
+		t.Errorf("expected legacy schema version 0, got %d", version)
+	}
+
+	// Open upgrades automatically and keeps existing mail
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	version, err = db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != LatestSchemaVersion() {
+		t.Errorf("expected schema version %d after upgrade, got %d", LatestSchemaVersion(), version)
+	}
+
+	inbox, err := db.GetInbox("dev", StatusUnread)
+	if err != nil {
+		t.Fatalf("GetInbox failed: %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].ID != "legacy1" {
+		t.Errorf("expected legacy message to survive upgrade, got %v", inbox)
+	}
+
+	// Existing mail is backfilled into the search index
+	results, err := db.Search("dev", SearchOptions{Query: "old"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected legacy message to be searchable, got %d results", len(results))
+	}
+}
+
+func TestMigrateMovesDeletedToTrash(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Build the schema as it was before the trash existed
+	old, err := OpenNoMigrate(dbPath)
+	if err != nil {
+		t.Fatalf("OpenNoMigrate failed: %v", err)
+	}
+	ctx := context.Background()
+	conn, err := old.conn.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to acquire connection: %v", err)
+	}
+	for _, m := range migrations {
+		if m.Name == "trash" {
+			break
+		}
+		if _, err := applyMigration(ctx, conn, m); err != nil {
+			t.Fatalf("applying migration %d failed: %v", m.Version, err)
+		}
+	}
+	_, err = conn.ExecContext(ctx, `INSERT INTO messages (id, from_id, subject, body, created_at) VALUES ('old1', 'pm', 'Old', 'Body', ?)`, time.Now())
+	if err == nil {
+		_, err = conn.ExecContext(ctx, `INSERT INTO recipients (message_id, to_id, status, deleted_at) VALUES ('old1', 'dev', 'deleted', ?)`, time.Now())
+	}
+	conn.Close()
+	old.Close()
+	if err != nil {
+		t.Fatalf("failed to insert deleted message: %v", err)
+	}
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	trash, _ := db.GetInbox("dev", StatusTrashed)
+	if len(trash) != 1 || trash[0].ID != "old1" {
+		t.Fatalf("expected deleted message in the trash, got %+v", trash)
+	}
+	recipients, _ := db.GetRecipientStatus("old1")
+	if len(recipients) != 1 || recipients[0].TrashedAt == nil {
+		t.Errorf("expected deleted_at to carry over as trashed_at, got %+v", recipients)
+	}
+	if events, _ := db.GetEvents(0, 0); len(events) != 1 || events[0].Type != "sent" {
+		t.Errorf("moving old deletions to the trash should not record events, got %+v", events)
+	}
+}
+
+func TestMigrateRejectsNewerSchema(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	future := LatestSchemaVersion() + 1
+	_, err := db.conn.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		future, "from the future", time.Now())
+	if err != nil {
+		t.Fatalf("failed to insert future version: %v", err)
+	}
+
+	if _, err := db.Migrate(); err == nil {
+		t.Error("expected Migrate to fail on a schema newer than this build")
+	}
+}
+
+func TestConcurrentOpenMigrates(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "amail-migrate-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	const numOpeners = 5
+	var wg sync.WaitGroup
+	errors := make(chan error, numOpeners)
+
+	for i := 0; i < numOpeners; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			db, err := Open(dbPath)
+			if err != nil {
+				errors <- fmt.Errorf("opener %d: %w", n, err)
+				return
+			}
+			db.Close()
+		}(i)
+	}
+
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Error(err)
+	}
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	var rows int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&rows); err != nil {
+		t.Fatalf("failed to count schema_version rows: %v", err)
+	}
+	if rows != len(migrations) {
+		t.Errorf("expected %d schema_version rows, got %d", len(migrations), rows)
+	}
+}
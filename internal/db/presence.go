@@ -0,0 +1,90 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Presence is the last sign of life from an identity: a role, or an
+// instance of one such as dev#1
+type Presence struct {
+	Name     string
+	Role     string
+	Host     string
+	PID      int
+	Tmux     string
+	LastSeen time.Time
+}
+
+// presenceInterval is how often an identity's presence is rewritten from
+// the same host and tmux session; last seen times are shown to the minute
+const presenceInterval = time.Minute
+
+// RecordPresence records that an identity is alive, replacing its
+// previous host, pid and tmux session. Within presenceInterval of the
+// last record from the same place it does nothing.
+func (db *DB) RecordPresence(p Presence) error {
+	var last time.Time
+	err := db.conn.QueryRow(`
+		SELECT last_seen FROM presence WHERE name = ? AND host = ? AND tmux = ?`,
+		p.Name, p.Host, p.Tmux).Scan(&last)
+	if err == nil && p.LastSeen.Sub(last) < presenceInterval {
+		return nil
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get presence: %w", err)
+	}
+
+	_, err = db.conn.Exec(`
+		INSERT INTO presence (name, role, host, pid, tmux, last_seen) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+		    role = excluded.role, host = excluded.host, pid = excluded.pid,
+		    tmux = excluded.tmux, last_seen = excluded.last_seen`,
+		p.Name, p.Role, p.Host, p.PID, p.Tmux, p.LastSeen)
+	if err != nil {
+		return fmt.Errorf("failed to record presence: %w", err)
+	}
+	return nil
+}
+
+// GetPresence returns every identity seen, grouped by role and most
+// recently seen first
+func (db *DB) GetPresence() ([]Presence, error) {
+	rows, err := db.conn.Query(`
+		SELECT name, role, host, pid, tmux, last_seen FROM presence
+		ORDER BY role, ` + julianSQL("last_seen") + ` DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query presence: %w", err)
+	}
+	defer rows.Close()
+
+	var presence []Presence
+	for rows.Next() {
+		var p Presence
+		if err := rows.Scan(&p.Name, &p.Role, &p.Host, &p.PID, &p.Tmux, &p.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan presence: %w", err)
+		}
+		presence = append(presence, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating presence rows: %w", err)
+	}
+	return presence, nil
+}
+
+// LastSeen returns when any identity of role was last seen, or nil if it
+// never has been
+func (db *DB) LastSeen(role string) (*time.Time, error) {
+	var p Presence
+	err := db.conn.QueryRow(`
+		SELECT last_seen FROM presence WHERE role = ?
+		ORDER BY `+julianSQL("last_seen")+` DESC LIMIT 1`, role).Scan(&p.LastSeen)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last seen: %w", err)
+	}
+	return &p.LastSeen, nil
+}
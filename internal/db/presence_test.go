@@ -0,0 +1,58 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresence(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if seen, err := db.LastSeen("qa"); err != nil || seen != nil {
+		t.Fatalf("expected qa never seen, got %v, %v", seen, err)
+	}
+
+	earlier := time.Now().Add(-time.Hour)
+	db.RecordPresence(Presence{Name: "dev#1", Role: "dev", Host: "box", PID: 10, LastSeen: earlier})
+	db.RecordPresence(Presence{Name: "dev#2", Role: "dev", Host: "box", PID: 20, Tmux: "proj-dev", LastSeen: time.Now()})
+
+	// A later invocation replaces the row, but not within a minute of it
+	if err := db.RecordPresence(Presence{Name: "dev#1", Role: "dev", Host: "box", PID: 11, LastSeen: earlier.Add(time.Minute)}); err != nil {
+		t.Fatalf("RecordPresence failed: %v", err)
+	}
+	if err := db.RecordPresence(Presence{Name: "dev#1", Role: "dev", Host: "box", PID: 12, LastSeen: earlier.Add(90 * time.Second)}); err != nil {
+		t.Fatalf("RecordPresence failed: %v", err)
+	}
+
+	presence, err := db.GetPresence()
+	if err != nil {
+		t.Fatalf("GetPresence failed: %v", err)
+	}
+	if len(presence) != 2 || presence[0].Name != "dev#2" || presence[1].PID != 11 {
+		t.Fatalf("expected dev#2 then updated dev#1, got %+v", presence)
+	}
+
+	seen, _ := db.LastSeen("dev")
+	if seen == nil || !seen.Equal(presence[0].LastSeen) {
+		t.Errorf("expected dev last seen with dev#2, got %v", seen)
+	}
+}
+
+func TestPresenceAcrossZones(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// As text, the older record from a zone ahead sorts after the newer one
+	now := time.Now()
+	db.RecordPresence(Presence{Name: "dev#1", Role: "dev", Host: "east", LastSeen: now.Add(-2 * time.Hour).In(time.FixedZone("LINT", 14*3600))})
+	db.RecordPresence(Presence{Name: "dev#2", Role: "dev", Host: "west", LastSeen: now.In(time.FixedZone("HST", -10*3600))})
+
+	presence, _ := db.GetPresence()
+	if len(presence) != 2 || presence[0].Name != "dev#2" {
+		t.Errorf("expected dev#2 first, got %+v", presence)
+	}
+	if seen, _ := db.LastSeen("dev"); seen == nil || now.Sub(*seen) > time.Second {
+		t.Errorf("expected dev last seen now, got %v", seen)
+	}
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Request states, tracked per recipient of a 'request' message
+const (
+	RequestOpen         = "open"
+	RequestAcknowledged = "acknowledged"
+	RequestInProgress   = "in-progress"
+	RequestDone         = "done"
+	RequestDeclined     = "declined"
+)
+
+// OpenRequestStates are the states of a request still waiting on its recipient
+var OpenRequestStates = []string{RequestOpen, RequestAcknowledged, RequestInProgress}
+
+// IsValidRequestState reports whether state is a known request state
+func IsValidRequestState(state string) bool {
+	switch state {
+	case RequestOpen, RequestAcknowledged, RequestInProgress, RequestDone, RequestDeclined:
+		return true
+	}
+	return false
+}
+
+// SetRequestState moves a recipient's copy of a request to a new state.
+// Returns false if the message is not a request to toID.
+func (db *DB) SetRequestState(messageID, toID, state string) (bool, error) {
+	if !IsValidRequestState(state) {
+		return false, fmt.Errorf("invalid request state: %s", state)
+	}
+
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET request_state = ?
+		WHERE message_id = ? AND to_id = ? AND request_state IS NOT NULL`,
+		state, messageID, toID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update request state: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// GetTasks returns the requests sent to toID whose state is one of states,
+// oldest first. Trashed, recalled and snoozed requests are left out.
+func (db *DB) GetTasks(toID string, states ...string) ([]InboxMessage, error) {
+	if len(states) == 0 {
+		return nil, fmt.Errorf("no states given")
+	}
+
+	placeholders, args := inClause(states)
+	rows, err := db.conn.Query(sessionCTE+`
+		SELECT m.id, m.from_id, m.subject, m.body, `+prioritySQL+`, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at,
+		       `+sessionStatusSQL+`, r.read_at, r.starred_at, r.request_state
+		FROM messages m
+		JOIN recipients r ON m.id = r.message_id
+		WHERE r.to_id = ? AND r.request_state IN (`+placeholders+`)
+		  AND r.status NOT IN ('trashed', 'recalled')
+		  AND `+deliveredSQL+` AND `+awakeSQL+`
+		ORDER BY m.created_at ASC`,
+		append(append(db.sessionArgs(), toID), args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks, messageIDs, err := scanInboxRows(rows, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tasks: %w", err)
+	}
+
+	if err := db.attachRecipients(tasks, messageIDs); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetPending returns the requests fromID sent that some recipient has not
+// finished or declined yet, oldest first. Recalled requests are left out.
+func (db *DB) GetPending(fromID string) ([]SentMessage, error) {
+	sent, err := db.GetSent(fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []SentMessage
+	for i := len(sent) - 1; i >= 0; i-- {
+		s := sent[i]
+		if s.MsgType != "request" || s.RecalledAt != nil {
+			continue
+		}
+		for _, r := range s.Recipients {
+			if isOpenRequestState(r.RequestState) && r.Status != StatusRecalled {
+				pending = append(pending, s)
+				break
+			}
+		}
+	}
+	return pending, nil
+}
+
+// isOpenRequestState reports whether state is one of OpenRequestStates
+func isOpenRequestState(state string) bool {
+	for _, s := range OpenRequestStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Overdue reports whether the message is a request past its deadline that
+// the recipient has not finished or declined
+func (m *InboxMessage) Overdue(now time.Time) bool {
+	return m.DueAt != nil && now.After(*m.DueAt) && isOpenRequestState(m.RequestState)
+}
+
+// GetOverdue returns the requests fromID sent that are past due, each with
+// only the recipients still working on it who have not been escalated yet
+func (db *DB) GetOverdue(fromID string, now time.Time) ([]SentMessage, error) {
+	return db.getOverdue(fromID, now)
+}
+
+// GetAllOverdue returns the past due requests of every sender, like
+// GetOverdue
+func (db *DB) GetAllOverdue(now time.Time) ([]SentMessage, error) {
+	return db.getOverdue("", now)
+}
+
+// getOverdue finds past due requests from fromID, or from anyone if empty,
+// oldest first
+func (db *DB) getOverdue(fromID string, now time.Time) ([]SentMessage, error) {
+	placeholders, stateArgs := inClause(OpenRequestStates)
+	args := append([]interface{}{sqlTime(now), fromID, fromID}, stateArgs...)
+	rows, err := db.conn.Query(`
+		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.edited_at, m.recalled_at, m.due_at
+		FROM messages m
+		WHERE m.msg_type = 'request' AND m.recalled_at IS NULL AND `+deliveredSQL+`
+		  AND m.due_at IS NOT NULL AND `+julianSQL("m.due_at")+` < julianday(?)
+		  AND (? = '' OR m.from_id = ?)
+		  AND EXISTS (SELECT 1 FROM recipients r
+		              WHERE r.message_id = m.id AND r.request_state IN (`+placeholders+`)
+		                AND r.status != 'recalled' AND r.escalated_at IS NULL)
+		ORDER BY m.created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query overdue requests: %w", err)
+	}
+	defer rows.Close()
+
+	messages, messageIDs, err := scanInboxRows(rows, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan overdue requests: %w", err)
+	}
+
+	details, err := db.getRecipientDetailsForMessages(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	overdue := make([]SentMessage, len(messages))
+	for i, msg := range messages {
+		var waiting []Recipient
+		for _, r := range details[msg.ID] {
+			msg.ToIDs = append(msg.ToIDs, r.ToID)
+			if isOpenRequestState(r.RequestState) && r.Status != StatusRecalled && r.EscalatedAt == nil {
+				waiting = append(waiting, r)
+			}
+		}
+		overdue[i] = SentMessage{InboxMessage: msg, Recipients: waiting}
+	}
+	return overdue, nil
+}
+
+// MarkEscalated records that a recipient's overdue request was escalated.
+// Returns false if it already was, so only one watcher escalates it.
+func (db *DB) MarkEscalated(messageID, toID string) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET escalated_at = ?
+		WHERE message_id = ? AND to_id = ? AND escalated_at IS NULL`,
+		time.Now(), messageID, toID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark escalated: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestLifecycle(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	db.SendMessage(&Message{
+		ID:        "req001",
+		FromID:    "pm",
+		Subject:   "Build login",
+		Body:      "Please",
+		Priority:  "high",
+		MsgType:   "request",
+		CreatedAt: now.Add(-time.Minute),
+	}, []string{"dev", "qa"})
+	db.SendMessage(&Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "FYI",
+		Body:      "Not a request",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: now,
+	}, []string{"dev"})
+
+	tasks, err := db.GetTasks("dev", OpenRequestStates...)
+	if err != nil {
+		t.Fatalf("GetTasks failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "req001" || tasks[0].RequestState != RequestOpen {
+		t.Fatalf("expected req001 open for dev, got %+v", tasks)
+	}
+
+	// Only requests have a state
+	if ok, _ := db.SetRequestState("msg001", "dev", RequestDone); ok {
+		t.Error("expected plain message to have no request state")
+	}
+	if _, err := db.SetRequestState("req001", "dev", "finished"); err == nil {
+		t.Error("expected invalid state to be rejected")
+	}
+
+	if ok, err := db.SetRequestState("req001", "dev", RequestInProgress); err != nil || !ok {
+		t.Fatalf("SetRequestState failed: %v, %v", ok, err)
+	}
+	db.SetRequestState("req001", "qa", RequestDeclined)
+
+	pending, err := db.GetPending("pm")
+	if err != nil {
+		t.Fatalf("GetPending failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "req001" {
+		t.Fatalf("expected req001 pending on dev, got %+v", pending)
+	}
+
+	// A response from dev closes dev's copy
+	replyTo := "req001"
+	db.SendMessage(&Message{
+		ID:        "resp001",
+		FromID:    "dev",
+		Subject:   "RE: Build login",
+		Body:      "Done",
+		Priority:  "normal",
+		MsgType:   "response",
+		ReplyToID: &replyTo,
+		ThreadID:  &replyTo,
+		CreatedAt: now.Add(time.Second),
+	}, []string{"pm"})
+
+	if tasks, _ := db.GetTasks("dev", OpenRequestStates...); len(tasks) != 0 {
+		t.Errorf("expected no open tasks for dev, got %+v", tasks)
+	}
+	if tasks, _ := db.GetTasks("dev", RequestDone); len(tasks) != 1 {
+		t.Errorf("expected one done task for dev, got %+v", tasks)
+	}
+	if pending, _ := db.GetPending("pm"); len(pending) != 0 {
+		t.Errorf("expected nothing pending for pm, got %+v", pending)
+	}
+
+	var types []string
+	events, _ := db.GetEvents(0, 0)
+	for _, e := range events {
+		if e.MessageID == "req001" && e.Type != "sent" {
+			types = append(types, e.Actor+":"+e.Type)
+		}
+	}
+	want := []string{"dev:in-progress", "qa:declined", "dev:done"}
+	if len(types) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("event %d: expected %s, got %s", i, want[i], types[i])
+		}
+	}
+}
+
+func TestOverdueAndEscalation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	due := now.Add(-time.Minute)
+	db.SendMessage(&Message{
+		ID:        "req001",
+		FromID:    "pm",
+		Subject:   "Review PR",
+		Body:      "By noon",
+		Priority:  "normal",
+		MsgType:   "request",
+		CreatedAt: now.Add(-time.Hour),
+		DueAt:     &due,
+	}, []string{"dev", "qa"})
+	later := now.Add(time.Hour)
+	db.SendMessage(&Message{
+		ID:        "req002",
+		FromID:    "pm",
+		Subject:   "Later",
+		Body:      "No rush",
+		Priority:  "normal",
+		MsgType:   "request",
+		CreatedAt: now,
+		DueAt:     &later,
+	}, []string{"dev"})
+	db.SetRequestState("req001", "qa", RequestDone)
+
+	inbox, _ := db.GetInbox("dev", StatusUnread)
+	for _, m := range inbox {
+		if m.DueAt == nil {
+			t.Fatalf("expected %s to carry its due time", m.ID)
+		}
+		if got := m.Overdue(now); got != (m.ID == "req001") {
+			t.Errorf("%s: Overdue() = %v", m.ID, got)
+		}
+	}
+
+	overdue, err := db.GetOverdue("pm", now)
+	if err != nil {
+		t.Fatalf("GetOverdue failed: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].ID != "req001" || len(overdue[0].Recipients) != 1 || overdue[0].Recipients[0].ToID != "dev" {
+		t.Fatalf("expected req001 overdue on dev only, got %+v", overdue)
+	}
+	if all, err := db.GetAllOverdue(now); err != nil || len(all) != 1 || all[0].ID != "req001" {
+		t.Errorf("GetAllOverdue = %+v, %v; want req001", all, err)
+	}
+
+	if ok, err := db.MarkEscalated("req001", "dev"); err != nil || !ok {
+		t.Fatalf("MarkEscalated failed: %v, %v", ok, err)
+	}
+	if ok, _ := db.MarkEscalated("req001", "dev"); ok {
+		t.Error("expected second escalation to be refused")
+	}
+	if overdue, _ := db.GetOverdue("pm", now); len(overdue) != 0 {
+		t.Errorf("expected nothing left to escalate, got %+v", overdue)
+	}
+
+	events, _ := db.GetEvents(0, 0)
+	last := events[len(events)-1]
+	if last.Type != "escalated" || last.Actor != "dev" {
+		t.Errorf("expected escalated event for dev, got %+v", last)
+	}
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Revision is an earlier body of a message that its sender has edited
+type Revision struct {
+	Number    int
+	Body      string
+	CreatedAt time.Time // When this body was written
+}
+
+// EditMessage replaces the body of a message from fromID, keeping the
+// previous body as a revision. Returns false if the message is not fromID's
+// or has been recalled.
+func (db *DB) EditMessage(messageID, fromID, body string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldBody string
+	var createdAt time.Time
+	var editedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT body, created_at, edited_at FROM messages
+		WHERE id = ? AND from_id = ? AND recalled_at IS NULL`,
+		messageID, fromID).Scan(&oldBody, &createdAt, &editedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	// The old body was written when the message was sent or last edited
+	writtenAt := createdAt
+	if editedAt.Valid {
+		writtenAt = editedAt.Time
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO revisions (message_id, revision, body, created_at)
+		SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ? FROM revisions WHERE message_id = ?`,
+		messageID, oldBody, writtenAt, messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE messages SET body = ?, edited_at = ? WHERE id = ?`,
+		body, time.Now(), messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	// Keep search in step with the latest body
+	_, err = tx.Exec(`UPDATE messages_fts SET body = ? WHERE message_id = ?`, body, messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reindex message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// GetRevisions returns the earlier bodies of a message, oldest first. The
+// current body is not included.
+func (db *DB) GetRevisions(messageID string) ([]Revision, error) {
+	rows, err := db.conn.Query(`
+		SELECT revision, body, created_at FROM revisions
+		WHERE message_id = ?
+		ORDER BY revision`, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []Revision
+	for rows.Next() {
+		var r Revision
+		if err := rows.Scan(&r.Number, &r.Body, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revision rows: %w", err)
+	}
+	return revisions, nil
+}
+
+// RecallMessage withdraws a message from fromID. Recipients who have not
+// read it yet lose it from their inbox; the rest keep it marked recalled.
+// Returns false if the message is not fromID's or was already recalled.
+func (db *DB) RecallMessage(messageID, fromID string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE messages SET recalled_at = ?
+		WHERE id = ? AND from_id = ? AND recalled_at IS NULL`,
+		sqlTime(time.Now()), messageID, fromID)
+	if err != nil {
+		return false, fmt.Errorf("failed to recall message: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE recipients SET status = 'recalled'
+		WHERE message_id = ? AND status = 'unread'`, messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to withdraw message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// withholdRecalled blanks the body and attachments of recalled messages
+// that viewer did not send and has no copy of left, which is every one for
+// an empty viewer
+func (db *DB) withholdRecalled(messages []InboxMessage, viewer string) error {
+	var recalled []string
+	for _, m := range messages {
+		if m.RecalledAt != nil && m.FromID != viewer {
+			recalled = append(recalled, m.ID)
+		}
+	}
+	if len(recalled) == 0 {
+		return nil
+	}
+
+	placeholders, args := inClause(recalled)
+	rows, err := db.conn.Query(`
+		SELECT message_id FROM recipients
+		WHERE to_id = ? AND status != 'recalled' AND message_id IN (`+placeholders+`)`,
+		append([]interface{}{viewer}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to query recalled copies: %w", err)
+	}
+	defer rows.Close()
+
+	kept := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to scan recalled copy: %w", err)
+		}
+		kept[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating recalled copies: %w", err)
+	}
+
+	for i := range messages {
+		m := &messages[i]
+		if m.RecalledAt != nil && m.FromID != viewer && !kept[m.ID] {
+			m.Body = ""
+			m.Attachments = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEditMessage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.SendMessage(&Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Endpoint",
+		Body:      "Use /api/v1/users",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}, []string{"dev"})
+
+	// Only the sender can edit
+	if ok, err := db.EditMessage("msg001", "dev", "hijacked"); err != nil || ok {
+		t.Fatalf("expected edit by non-sender to be refused, got %v, %v", ok, err)
+	}
+
+	for _, body := range []string{"Use /api/v2/users", "Use /api/v3/users"} {
+		if ok, err := db.EditMessage("msg001", "pm", body); err != nil || !ok {
+			t.Fatalf("EditMessage failed: %v, %v", ok, err)
+		}
+	}
+
+	msg, _ := db.GetMessageForRecipient("msg001", "dev")
+	if msg == nil || msg.Body != "Use /api/v3/users" || msg.EditedAt == nil {
+		t.Fatalf("expected latest body and edit time, got %+v", msg)
+	}
+
+	revisions, err := db.GetRevisions("msg001")
+	if err != nil {
+		t.Fatalf("GetRevisions failed: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Body != "Use /api/v1/users" || revisions[1].Number != 2 {
+		t.Errorf("expected two earlier revisions, got %+v", revisions)
+	}
+
+	// Search finds the latest body only
+	if results, _ := db.Search("dev", SearchOptions{Query: "v3"}); len(results) != 1 {
+		t.Errorf("expected search to find the edited body, got %d results", len(results))
+	}
+	if results, _ := db.Search("dev", SearchOptions{Query: "v1"}); len(results) != 0 {
+		t.Errorf("expected old body to be unindexed, got %d results", len(results))
+	}
+
+	events, _ := db.GetEvents(0, 0)
+	if len(events) != 3 || events[1].Type != "edited" || events[1].Actor != "pm" {
+		t.Errorf("expected sent and two edited events, got %+v", events)
+	}
+}
+
+func TestRecallMessage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.SendMessage(&Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Wrong path",
+		Body:      "See src/old.go",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}, []string{"dev", "qa"})
+	db.MarkRead("msg001", "qa")
+
+	if ok, _ := db.RecallMessage("msg001", "dev"); ok {
+		t.Fatal("expected recall by non-sender to be refused")
+	}
+	if ok, err := db.RecallMessage("msg001", "pm"); err != nil || !ok {
+		t.Fatalf("RecallMessage failed: %v, %v", ok, err)
+	}
+	if ok, _ := db.RecallMessage("msg001", "pm"); ok {
+		t.Error("expected second recall to be refused")
+	}
+
+	// Unread copies are withdrawn
+	if inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead); len(inbox) != 0 {
+		t.Errorf("expected recalled message gone from dev's inbox, got %+v", inbox)
+	}
+	if count, _ := db.CountUnread("dev"); count != 0 {
+		t.Errorf("expected no unread for dev, got %d", count)
+	}
+
+	// Read copies stay, marked recalled
+	inbox, _ := db.GetInbox("qa", StatusRead)
+	if len(inbox) != 1 || inbox[0].RecalledAt == nil {
+		t.Errorf("expected qa to keep a recalled copy, got %+v", inbox)
+	}
+
+	recipients, _ := db.GetRecipientStatus("msg001")
+	for _, r := range recipients {
+		want := StatusRead
+		if r.ToID == "dev" {
+			want = StatusRecalled
+		}
+		if r.Status != want {
+			t.Errorf("expected %s to be %s, got %s", r.ToID, want, r.Status)
+		}
+	}
+
+	// Only the sender and copies kept can still read it in the thread
+	for viewer, body := range map[string]string{"pm": "See src/old.go", "qa": "See src/old.go", "dev": "", "": ""} {
+		thread, err := db.GetThread("msg001", viewer)
+		if err != nil || len(thread) != 1 || thread[0].Body != body {
+			t.Errorf("expected %q to see body %q, got %+v, %v", viewer, body, thread, err)
+		}
+	}
+
+	// A recalled message can no longer be edited
+	if ok, _ := db.EditMessage("msg001", "pm", "See src/new.go"); ok {
+		t.Error("expected edit of recalled message to be refused")
+	}
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Rule acts on incoming mail as it is delivered. Conditions left empty
+// match anything; actions apply to the matching recipient's copy.
+type Rule struct {
+	Name string
+
+	// Conditions
+	From     string
+	To       string
+	Subject  *regexp.Regexp
+	Body     *regexp.Regexp
+	Priority string
+	MsgType  string
+
+	// Actions
+	Labels      []string
+	Archive     bool
+	MarkRead    bool
+	ForwardTo   string
+	SetPriority string // Changes the priority of the recipient's copy
+	Notify      string // Notify command set for watch to use instead of the priority's
+}
+
+// Matches reports whether the rule applies to msg as delivered to toID
+func (r *Rule) Matches(msg *Message, toID string) bool {
+	switch {
+	case r.From != "" && r.From != msg.FromID:
+		return false
+	case r.To != "" && r.To != toID:
+		return false
+	case r.Priority != "" && r.Priority != msg.Priority:
+		return false
+	case r.MsgType != "" && r.MsgType != msg.MsgType:
+		return false
+	case r.Subject != nil && !r.Subject.MatchString(msg.Subject):
+		return false
+	case r.Body != nil && !r.Body.MatchString(msg.Body):
+		return false
+	}
+	return true
+}
+
+// MatchRules returns the rules that apply to msg as delivered to toID, in
+// order
+func MatchRules(rules []Rule, msg *Message, toID string) []Rule {
+	var matched []Rule
+	for _, r := range rules {
+		if r.Matches(msg, toID) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// prioritySQL is a message's priority as recipient r sees it, after any
+// rule changed it. Queries using it join messages m and recipients r.
+const prioritySQL = `COALESCE(r.priority_set, m.priority)`
+
+// UseRules sets the rules SendMessage applies to new mail
+func (db *DB) UseRules(rules []Rule) {
+	db.rules = rules
+}
+
+// applyRules runs every matching rule on each recipient's copy of msg.
+// Rules match the message as sent, so a priority change, which applies to
+// the copy and what it forwards, does not affect which later rules match.
+// Forwards a rule makes are not matched against rules again.
+func applyRules(tx *sql.Tx, rules []Rule, msg *Message, recipients []string, now time.Time) error {
+	for _, toID := range recipients {
+		fwd := *msg
+		for _, r := range MatchRules(rules, msg, toID) {
+			if err := applyRule(tx, &r, &fwd, recipients, toID, now); err != nil {
+				return fmt.Errorf("failed to apply rule %q: %w", r.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func applyRule(tx *sql.Tx, r *Rule, msg *Message, recipients []string, toID string, now time.Time) error {
+	for _, label := range r.Labels {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO labels (message_id, to_id, label) VALUES (?, ?, ?)`,
+			msg.ID, toID, label)
+		if err != nil {
+			return fmt.Errorf("failed to add label: %w", err)
+		}
+	}
+
+	if r.MarkRead {
+		_, err := tx.Exec(`
+			UPDATE recipients SET status = 'read', read_at = ?
+			WHERE message_id = ? AND to_id = ? AND status = 'unread'`,
+			now, msg.ID, toID)
+		if err != nil {
+			return fmt.Errorf("failed to mark as read: %w", err)
+		}
+		// Role instances keep their own read state
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO session_reads (message_id, session_id, read_at)
+			SELECT ?, id, ? FROM sessions WHERE role = ?`,
+			msg.ID, now, toID)
+		if err != nil {
+			return fmt.Errorf("failed to mark as read: %w", err)
+		}
+	}
+
+	if r.Archive {
+		_, err := tx.Exec(`
+			UPDATE recipients SET status = 'archived', archived_at = ?
+			WHERE message_id = ? AND to_id = ?`,
+			now, msg.ID, toID)
+		if err != nil {
+			return fmt.Errorf("failed to archive: %w", err)
+		}
+	}
+
+	if r.Notify != "" {
+		_, err := tx.Exec(`
+			UPDATE recipients SET notify_set = ?
+			WHERE message_id = ? AND to_id = ?`,
+			r.Notify, msg.ID, toID)
+		if err != nil {
+			return fmt.Errorf("failed to set notify commands: %w", err)
+		}
+	}
+
+	if r.SetPriority != "" {
+		_, err := tx.Exec(`
+			UPDATE recipients SET priority_set = ?
+			WHERE message_id = ? AND to_id = ?`,
+			r.SetPriority, msg.ID, toID)
+		if err != nil {
+			return fmt.Errorf("failed to change priority: %w", err)
+		}
+		msg.Priority = r.SetPriority
+	}
+
+	// Forward unless the target wrote, already received or is the copy
+	// being forwarded
+	if r.ForwardTo != "" && r.ForwardTo != msg.FromID && r.ForwardTo != toID && !containsString(recipients, r.ForwardTo) {
+		note := fmt.Sprintf("Forwarded by rule %q.", r.Name)
+		if err := forward(tx, nil, msg, toID, r.ForwardTo, note, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// attachNotifySets sets NotifySet on a recipient's messages from the rules
+// that matched them
+func (db *DB) attachNotifySets(messages []InboxMessage, toID string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]string, len(messages))
+	for i := range messages {
+		ids[i] = messages[i].ID
+	}
+	placeholders, args := inClause(ids)
+
+	rows, err := db.conn.Query(`
+		SELECT message_id, notify_set FROM recipients
+		WHERE to_id = ? AND notify_set IS NOT NULL AND message_id IN (`+placeholders+`)`,
+		append([]interface{}{toID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to query notify sets: %w", err)
+	}
+	defer rows.Close()
+
+	sets := make(map[string]string)
+	for rows.Next() {
+		var id, set string
+		if err := rows.Scan(&id, &set); err != nil {
+			return fmt.Errorf("failed to scan notify set: %w", err)
+		}
+		sets[id] = set
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating notify set rows: %w", err)
+	}
+
+	for i := range messages {
+		messages[i].NotifySet = sets[messages[i].ID]
+	}
+	return nil
+}
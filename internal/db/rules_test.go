@@ -0,0 +1,101 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRuleMatches(t *testing.T) {
+	msg := &Message{FromID: "qa", Subject: "Flaky test", Body: "login_test failed", Priority: "normal", MsgType: "notification"}
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		toID     string
+		expected bool
+	}{
+		{"empty rule", Rule{}, "dev", true},
+		{"from", Rule{From: "qa"}, "dev", true},
+		{"wrong from", Rule{From: "pm"}, "dev", false},
+		{"to", Rule{To: "dev"}, "dev", true},
+		{"wrong to", Rule{To: "dev"}, "pm", false},
+		{"type and priority", Rule{MsgType: "notification", Priority: "normal"}, "dev", true},
+		{"wrong type", Rule{MsgType: "request"}, "dev", false},
+		{"subject regex", Rule{Subject: regexp.MustCompile(`(?i)^flaky`)}, "dev", true},
+		{"body regex", Rule{Body: regexp.MustCompile(`_test`)}, "dev", true},
+		{"body mismatch", Rule{Body: regexp.MustCompile(`panic`)}, "dev", false},
+		{"all conditions", Rule{From: "qa", To: "dev", MsgType: "notification", Subject: regexp.MustCompile(`test`)}, "dev", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(msg, tt.toID); got != tt.expected {
+				t.Errorf("Matches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSendMessageAppliesRules(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.UseRules([]Rule{
+		{Name: "quiet", MsgType: "notification", To: "dev", Labels: []string{"fyi"}, MarkRead: true},
+		{Name: "bots", From: "qa", Subject: regexp.MustCompile(`CI`), Archive: true, SetPriority: "low"},
+		{Name: "escalate", Body: regexp.MustCompile(`outage`), SetPriority: "high", ForwardTo: "research", Notify: "urgent"},
+		{Name: "as sent", Priority: "high", Archive: true},
+	})
+
+	now := time.Now()
+	send := func(id, from, subject, body, msgType string, to ...string) {
+		t.Helper()
+		err := db.SendMessage(&Message{
+			ID: id, FromID: from, Subject: subject, Body: body,
+			Priority: "normal", MsgType: msgType, CreatedAt: now,
+		}, to)
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+
+	// Labelled and read for dev only; pm still sees it unread
+	send("msg001", "pm", "Deployed", "v1.2 is out", "notification", "dev", "pm2")
+	read, _ := db.GetInbox("dev", StatusRead)
+	if len(read) != 1 || !read[0].HasLabel("fyi") {
+		t.Fatalf("expected msg001 read and labelled for dev, got %+v", read)
+	}
+	if unread, _ := db.GetInbox("pm2", StatusUnread); len(unread) != 1 {
+		t.Errorf("expected msg001 unread for pm2, got %d", len(unread))
+	}
+	if unnotified, _ := db.GetUnnotified("dev"); len(unnotified) != 0 {
+		t.Errorf("expected no notification for a message marked read, got %d", len(unnotified))
+	}
+
+	// Archived, with the priority lowered for dev's copy only
+	send("msg002", "qa", "CI green", "All passing", "message", "dev")
+	archived, _ := db.GetInbox("dev", StatusArchived)
+	if len(archived) != 1 || archived[0].Priority != "low" {
+		t.Fatalf("expected msg002 archived at low priority, got %+v", archived)
+	}
+	if sent, _ := db.GetSent("qa"); len(sent) != 1 || sent[0].Priority != "normal" {
+		t.Errorf("expected qa to see msg002 as sent, got %+v", sent)
+	}
+
+	// Raised and forwarded in the same thread, and notified with the urgent
+	// set; rules after the change still match the priority as sent
+	send("msg003", "pm", "Heads up", "Possible outage", "message", "dev")
+	forwarded, _ := db.GetInbox("research", StatusUnread)
+	if len(forwarded) != 1 || forwarded[0].FromID != "dev" || forwarded[0].Priority != "high" || forwarded[0].ThreadID == nil || *forwarded[0].ThreadID != "msg003" {
+		t.Fatalf("expected msg003 forwarded to research in its thread, got %+v", forwarded)
+	}
+	unnotified, _ := db.GetUnnotified("dev")
+	if len(unnotified) != 1 || unnotified[0].Priority != "high" || unnotified[0].NotifySet != "urgent" {
+		t.Errorf("expected msg003 to notify with the urgent set, got %+v", unnotified)
+	}
+	// The forward itself is not matched again
+	if unnotified, _ := db.GetUnnotified("research"); len(unnotified) != 1 || unnotified[0].NotifySet != "" {
+		t.Errorf("expected the forward to keep default notification, got %+v", unnotified)
+	}
+}
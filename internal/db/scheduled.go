@@ -0,0 +1,226 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// sqlTimeFormat matches SQLite's strftime('%Y-%m-%d %H:%M:%S'), so times
+// stored in it compare correctly as text against 'now'
+const sqlTimeFormat = "2006-01-02 15:04:05"
+
+// deliveredSQL restricts a query on messages m to those already delivered
+const deliveredSQL = `(m.deliver_at IS NULL OR m.deliver_at <= strftime('%Y-%m-%d %H:%M:%S', 'now'))`
+
+// pendingSQL restricts a query on messages m to scheduled messages not yet due
+const pendingSQL = `m.deliver_at > strftime('%Y-%m-%d %H:%M:%S', 'now')`
+
+// sqlTime formats t in UTC for the deliver_at and snoozed_until columns
+func sqlTime(t time.Time) string {
+	return t.UTC().Format(sqlTimeFormat)
+}
+
+// ScheduledMessage is a message queued for delivery at a later time
+type ScheduledMessage struct {
+	InboxMessage
+	DeliverAt time.Time
+}
+
+// GetScheduled returns messages from fromID that are not yet due, soonest first
+func (db *DB) GetScheduled(fromID string) ([]ScheduledMessage, error) {
+	rows, err := db.conn.Query(`
+		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, m.deliver_at
+		FROM messages m
+		WHERE m.from_id = ? AND `+pendingSQL+`
+		ORDER BY m.deliver_at ASC`, fromID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled: %w", err)
+	}
+	defer rows.Close()
+
+	var scheduled []ScheduledMessage
+	var messageIDs []string
+	for rows.Next() {
+		var s ScheduledMessage
+		var threadID, replyToID sql.NullString
+		err := rows.Scan(
+			&s.ID, &s.FromID, &s.Subject, &s.Body, &s.Priority, &s.MsgType,
+			&threadID, &replyToID, &s.CreatedAt, &s.DeliverAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled: %w", err)
+		}
+		if threadID.Valid {
+			s.ThreadID = &threadID.String
+		}
+		if replyToID.Valid {
+			s.ReplyToID = &replyToID.String
+		}
+		scheduled = append(scheduled, s)
+		messageIDs = append(messageIDs, s.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled rows: %w", err)
+	}
+
+	recipientMap, err := db.getRecipientsForMessages(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scheduled {
+		scheduled[i].ToIDs = recipientMap[scheduled[i].ID]
+	}
+
+	return scheduled, nil
+}
+
+// CancelScheduled deletes a scheduled message before it is delivered, and
+// the blobs of attachments no other message uses. Returns false if the
+// message is not pending from fromID, and true with an error if it was
+// cancelled but its blobs could not be removed.
+func (db *DB) CancelScheduled(messageID, fromID string) (bool, error) {
+	start := time.Now()
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var hashes []string
+	rows, err := tx.Query(`SELECT DISTINCT hash FROM attachments WHERE message_id = ?`, messageID)
+	if err != nil {
+		return false, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("error iterating attachment rows: %w", err)
+	}
+
+	// Recipients and attachments cascade
+	result, err := tx.Exec(`
+		DELETE FROM messages AS m
+		WHERE m.id = ? AND m.from_id = ? AND `+pendingSQL,
+		messageID, fromID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel message: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`DELETE FROM messages_fts WHERE message_id = ?`, messageID); err != nil {
+		return false, fmt.Errorf("failed to unindex message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := db.removeBlobs(hashes, start); err != nil {
+		return true, fmt.Errorf("message cancelled, but its attachments were not removed (run 'amail attachment gc'): %w", err)
+	}
+	return true, nil
+}
+
+// Reschedule moves the delivery time of a pending message from fromID.
+// Returns false if the message is not pending.
+func (db *DB) Reschedule(messageID, fromID string, deliverAt time.Time) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE messages AS m SET deliver_at = ?, created_at = ?
+		WHERE m.id = ? AND m.from_id = ? AND `+pendingSQL,
+		sqlTime(deliverAt), deliverAt, messageID, fromID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reschedule message: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// DeliverDue marks scheduled messages whose time has come as delivered,
+// recording their 'sent' events and forwarding and auto-replying for
+// recipients who are away by then. Queries already hide messages that
+// aren't due, so this only affects events, change notifications and away
+// handling.
+func (db *DB) DeliverDue() (int64, error) {
+	rows, err := db.conn.Query(`
+		SELECT id FROM messages
+		WHERE deliver_at IS NOT NULL
+		  AND deliver_at <= strftime('%Y-%m-%d %H:%M:%S', 'now')`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query scheduled messages: %w", err)
+	}
+	var due []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan scheduled message: %w", err)
+		}
+		due = append(due, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating scheduled messages: %w", err)
+	}
+
+	var delivered int64
+	for _, id := range due {
+		ok, err := db.deliverScheduled(id)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliverScheduled delivers a due message and handles its recipients who
+// are away. Returns false if another process delivered it first.
+func (db *DB) deliverScheduled(id string) (bool, error) {
+	msg, err := db.GetMessage(id)
+	if err != nil || msg == nil {
+		return false, err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE messages SET deliver_at = NULL WHERE id = ? AND deliver_at IS NOT NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to deliver scheduled message: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if msg.RecalledAt == nil {
+		if err := handleAway(tx, db.rules, &msg.Message, msg.ToIDs, time.Now()); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// NextDelivery returns when the next scheduled message is due, or nil if
+// none is pending
+func (db *DB) NextDelivery() (*time.Time, error) {
+	return db.minTime(`SELECT MIN(deliver_at) FROM messages WHERE deliver_at IS NOT NULL`)
+}
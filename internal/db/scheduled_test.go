@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scheduledMessage(id string, deliverAt time.Time) *Message {
+	return &Message{
+		ID:        id,
+		FromID:    "pm",
+		Subject:   "Handoff",
+		Body:      "End of day notes",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: deliverAt,
+		DeliverAt: &deliverAt,
+	}
+}
+
+func TestScheduledMessageHidden(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := db.SendMessage(scheduledMessage("msg001", time.Now().Add(time.Hour)), []string{"dev"}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead)
+	if len(inbox) != 0 {
+		t.Errorf("scheduled message leaked into inbox: %+v", inbox)
+	}
+	if count, _ := db.CountUnread("dev"); count != 0 {
+		t.Errorf("scheduled message counted as unread: %d", count)
+	}
+	if unnotified, _ := db.GetUnnotified("dev"); len(unnotified) != 0 {
+		t.Errorf("scheduled message returned as unnotified: %+v", unnotified)
+	}
+	if thread, _ := db.GetThread("msg001", "pm"); len(thread) != 0 {
+		t.Errorf("scheduled message leaked into thread: %+v", thread)
+	}
+	if n, _ := db.MarkAllRead("dev"); n != 0 {
+		t.Errorf("MarkAllRead touched %d scheduled messages", n)
+	}
+	if events, _ := db.GetEvents(0, 0); len(events) != 0 {
+		t.Errorf("expected no events before delivery, got %+v", events)
+	}
+
+	scheduled, err := db.GetScheduled("pm")
+	if err != nil {
+		t.Fatalf("GetScheduled failed: %v", err)
+	}
+	if len(scheduled) != 1 || scheduled[0].ID != "msg001" || len(scheduled[0].ToIDs) != 1 {
+		t.Fatalf("expected msg001 scheduled for dev, got %+v", scheduled)
+	}
+}
+
+func TestRescheduleAndDeliver(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.SendMessage(scheduledMessage("msg001", time.Now().Add(time.Hour)), []string{"dev"})
+
+	if ok, _ := db.Reschedule("msg001", "qa", time.Now()); ok {
+		t.Error("only the sender should be able to reschedule")
+	}
+	ok, err := db.Reschedule("msg001", "pm", time.Now().Add(-time.Minute))
+	if err != nil || !ok {
+		t.Fatalf("Reschedule failed: %v (ok=%v)", err, ok)
+	}
+
+	// Due now: visible even before delivery is recorded
+	if count, _ := db.CountUnread("dev"); count != 1 {
+		t.Errorf("expected due message to be unread, got count %d", count)
+	}
+
+	n, err := db.DeliverDue()
+	if err != nil || n != 1 {
+		t.Fatalf("DeliverDue = %d, %v; want 1", n, err)
+	}
+	events, _ := db.GetEvents(0, 0)
+	if len(events) != 1 || events[0].Type != "sent" {
+		t.Errorf("expected one sent event on delivery, got %+v", events)
+	}
+
+	// Delivered messages can no longer be changed
+	if ok, _ := db.Reschedule("msg001", "pm", time.Now().Add(time.Hour)); ok {
+		t.Error("delivered message should not be reschedulable")
+	}
+	if ok, _ := db.CancelScheduled("msg001", "pm"); ok {
+		t.Error("delivered message should not be cancellable")
+	}
+}
+
+func TestCancelScheduled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	only, size, _ := db.StoreBlob(strings.NewReader("only here"))
+	shared, _, _ := db.StoreBlob(strings.NewReader("shared"))
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(db.BlobPath(only), old, old)
+	os.Chtimes(db.BlobPath(shared), old, old)
+
+	msg := scheduledMessage("msg001", time.Now().Add(time.Hour))
+	msg.Attachments = []Attachment{{Name: "a.txt", Hash: only, Size: size}, {Name: "b.txt", Hash: shared, Size: size}}
+	db.SendMessage(msg, []string{"dev"})
+	db.SendMessage(&Message{
+		ID: "msg002", FromID: "pm", Subject: "Also", Body: "Body", Priority: "normal", MsgType: "message",
+		CreatedAt: time.Now(), Attachments: []Attachment{{Name: "b.txt", Hash: shared, Size: size}},
+	}, []string{"qa"})
+
+	ok, err := db.CancelScheduled("msg001", "pm")
+	if err != nil || !ok {
+		t.Fatalf("CancelScheduled failed: %v (ok=%v)", err, ok)
+	}
+
+	if msg, _ := db.GetMessage("msg001"); msg != nil {
+		t.Error("cancelled message should be gone")
+	}
+	if next, _ := db.NextDelivery(); next != nil {
+		t.Errorf("expected no pending delivery, got %v", next)
+	}
+
+	// Blobs only the cancelled message used are removed
+	for hash, wantExists := range map[string]bool{only: false, shared: true} {
+		_, err := os.Stat(db.BlobPath(hash))
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("blob %s exists = %v, want %v", hash[:8], exists, wantExists)
+		}
+	}
+}
+
+func TestSubscribeDeliversScheduled(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	db.SendMessage(scheduledMessage("msg001", time.Now().Add(1500*time.Millisecond)), []string{"dev"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := db.Subscribe(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for scheduled delivery")
+	}
+
+	if count, _ := db.CountUnread("dev"); count != 1 {
+		t.Errorf("expected delivered message to be unread, got count %d", count)
+	}
+	if next, _ := db.NextDelivery(); next != nil {
+		t.Errorf("expected delivery to be recorded, next delivery still %v", next)
+	}
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SearchOptions controls a full-text search
+type SearchOptions struct {
+	Query string
+	// Raw passes Query to FTS5 unchanged, allowing operators such as
+	// OR, NOT, NEAR and column filters. Otherwise each term is quoted.
+	Raw bool
+
+	From  string    // Only messages sent by this role
+	To    string    // Only messages received by this role
+	Since time.Time // Only messages created at or after this time
+	Limit int
+
+	// Markers placed around matching terms in the snippet
+	HighlightStart string
+	HighlightEnd   string
+}
+
+// SearchResult is a message matched by a full-text search
+type SearchResult struct {
+	InboxMessage
+	Snippet string
+	Score   float64 // Higher is more relevant
+}
+
+// Search finds messages matching a full-text query. Results are restricted to
+// mail the given identity sent or received, and ordered by relevance.
+func (db *DB) Search(identity string, opts SearchOptions) ([]SearchResult, error) {
+	match := opts.Query
+	if !opts.Raw {
+		match = ftsQuery(opts.Query)
+	}
+	if match == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// Subject matches weigh more than body matches; message_id is unindexed
+	query := `
+		SELECT m.id, m.from_id, m.subject, m.body, m.priority, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at,
+		       snippet(messages_fts, -1, ?, ?, '...', 12),
+		       bm25(messages_fts, 0.0, 10.0, 1.0)
+		FROM messages_fts
+		JOIN messages m ON m.id = messages_fts.message_id
+		WHERE messages_fts MATCH ?
+		  AND (m.from_id = ? OR (` + deliveredSQL + ` AND EXISTS (
+		      SELECT 1 FROM recipients r
+		      WHERE r.message_id = m.id AND r.to_id = ? AND r.status NOT IN ('trashed', 'recalled'))))`
+	args := []interface{}{opts.HighlightStart, opts.HighlightEnd, match, identity, identity}
+
+	if opts.From != "" {
+		query += ` AND m.from_id = ?`
+		args = append(args, opts.From)
+	}
+	if opts.To != "" {
+		query += ` AND EXISTS (
+		      SELECT 1 FROM recipients r WHERE r.message_id = m.id AND r.to_id = ?)`
+		args = append(args, opts.To)
+	}
+	if !opts.Since.IsZero() {
+		query += ` AND m.created_at >= ?`
+		args = append(args, opts.Since)
+	}
+
+	query += ` ORDER BY bm25(messages_fts, 0.0, 10.0, 1.0) LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	var messageIDs []string
+	for rows.Next() {
+		var res SearchResult
+		var threadID, replyToID sql.NullString
+		var rank float64
+
+		err := rows.Scan(
+			&res.ID, &res.FromID, &res.Subject, &res.Body, &res.Priority, &res.MsgType,
+			&threadID, &replyToID, &res.CreatedAt, &res.Snippet, &rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		if threadID.Valid {
+			res.ThreadID = &threadID.String
+		}
+		if replyToID.Valid {
+			res.ReplyToID = &replyToID.String
+		}
+		// bm25 returns lower values for better matches
+		res.Score = -rank
+
+		results = append(results, res)
+		messageIDs = append(messageIDs, res.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	recipientMap, err := db.getRecipientsForMessages(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].ToIDs = recipientMap[results[i].ID]
+	}
+
+	return results, nil
+}
+
+// ftsQuery turns free text into an FTS5 query that matches all terms.
+// Each term is quoted so punctuation can't be parsed as FTS5 syntax;
+// a trailing * on a term is kept as a prefix match.
+func ftsQuery(input string) string {
+	var terms []string
+	for _, term := range strings.Fields(input) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if term == "" {
+			continue
+		}
+
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+	return strings.Join(terms, " ")
+}
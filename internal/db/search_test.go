@@ -0,0 +1,179 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func seedSearchMessages(t *testing.T, db *DB) {
+	t.Helper()
+
+	messages := []struct {
+		msg        *Message
+		recipients []string
+	}{
+		{&Message{ID: "s001", FromID: "pm", Subject: "Auth endpoint", Body: "Please build the login flow",
+			Priority: "normal", MsgType: "request", CreatedAt: time.Now().Add(-48 * time.Hour)}, []string{"dev"}},
+		{&Message{ID: "s002", FromID: "dev", Subject: "Status", Body: "The auth work is half done",
+			Priority: "normal", MsgType: "message", CreatedAt: time.Now().Add(-time.Hour)}, []string{"pm"}},
+		{&Message{ID: "s003", FromID: "qa", Subject: "Test plan", Body: "Covering auth-token expiry",
+			Priority: "high", MsgType: "message", CreatedAt: time.Now()}, []string{"dev", "pm"}},
+		{&Message{ID: "s004", FromID: "pm", Subject: "Private auth note", Body: "Only for research",
+			Priority: "normal", MsgType: "message", CreatedAt: time.Now()}, []string{"research"}},
+	}
+
+	for _, m := range messages {
+		if err := db.SendMessage(m.msg, m.recipients); err != nil {
+			t.Fatalf("SendMessage %s failed: %v", m.msg.ID, err)
+		}
+	}
+}
+
+func resultIDs(results []SearchResult) map[string]bool {
+	ids := make(map[string]bool)
+	for _, r := range results {
+		ids[r.ID] = true
+	}
+	return ids
+}
+
+func TestSearchRespectsIdentity(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	seedSearchMessages(t, db)
+
+	results, err := db.Search("dev", SearchOptions{Query: "auth"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	ids := resultIDs(results)
+	// dev received s001 and s003, and sent s002
+	for _, want := range []string{"s001", "s002", "s003"} {
+		if !ids[want] {
+			t.Errorf("expected %s in dev's results", want)
+		}
+	}
+	if ids["s004"] {
+		t.Error("dev should not see a message sent from pm to research")
+	}
+}
+
+func TestSearchRanksSubjectMatches(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	seedSearchMessages(t, db)
+
+	results, err := db.Search("dev", SearchOptions{Query: "auth"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("expected results")
+	}
+	if results[0].ID != "s001" {
+		t.Errorf("expected subject match s001 first, got %s", results[0].ID)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("results not ordered by score: %v > %v", results[i].Score, results[i-1].Score)
+		}
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	seedSearchMessages(t, db)
+
+	results, err := db.Search("dev", SearchOptions{Query: "auth", From: "qa"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "s003" {
+		t.Errorf("expected only s003 from qa, got %v", resultIDs(results))
+	}
+
+	results, err = db.Search("dev", SearchOptions{Query: "auth", To: "pm"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	ids := resultIDs(results)
+	if len(ids) != 2 || !ids["s002"] || !ids["s003"] {
+		t.Errorf("expected s002 and s003 to pm, got %v", ids)
+	}
+
+	results, err = db.Search("dev", SearchOptions{Query: "auth", Since: time.Now().Add(-24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if resultIDs(results)["s001"] {
+		t.Error("expected --since to exclude the 48h old message")
+	}
+}
+
+func TestSearchSnippetHighlight(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	seedSearchMessages(t, db)
+
+	results, err := db.Search("dev", SearchOptions{
+		Query:          "login",
+		HighlightStart: "[",
+		HighlightEnd:   "]",
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Snippet != "Please build the [login] flow" {
+		t.Errorf("unexpected snippet: %q", results[0].Snippet)
+	}
+}
+
+func TestSearchQuotesPunctuation(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	seedSearchMessages(t, db)
+
+	// A hyphen would be parsed as FTS5 syntax if passed through raw
+	results, err := db.Search("dev", SearchOptions{Query: "auth-token"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "s003" {
+		t.Errorf("expected s003, got %v", resultIDs(results))
+	}
+
+	// Prefix search
+	results, err = db.Search("dev", SearchOptions{Query: "expir*"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "s003" {
+		t.Errorf("expected prefix match s003, got %v", resultIDs(results))
+	}
+}
+
+func TestFtsQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"auth", `"auth"`},
+		{"auth endpoint", `"auth" "endpoint"`},
+		{"auth-token", `"auth-token"`},
+		{`say "hi"`, `"say" """hi"""`},
+		{"migrat*", `"migrat"*`},
+		{"  ", ""},
+		{"*", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ftsQuery(tt.input); got != tt.want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
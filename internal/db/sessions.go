@@ -0,0 +1,120 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// sessionCTE starts queries that use sessionStatusSQL or
+// sessionNotifiedSQL. It binds the current session, so db.sessionArgs()
+// must come first in the query's arguments.
+const sessionCTE = `WITH cur(session, role) AS (SELECT ?, ?) `
+
+// sessionStatusSQL is a recipient's status as the current session sees it.
+// For the session's own role, unread and read come from session_reads,
+// except for mail delivered before the session started. Queries using it
+// join messages m and recipients r.
+var sessionStatusSQL = `(CASE
+	WHEN r.to_id != (SELECT role FROM cur) OR r.status NOT IN ('unread', 'read') THEN r.status
+	WHEN EXISTS (SELECT 1 FROM session_reads sr, cur
+	             WHERE sr.message_id = r.message_id AND sr.session_id = cur.session AND sr.read_at IS NOT NULL) THEN 'read'
+	WHEN ` + julianSQL("m.created_at") + ` < (SELECT ` + julianSQL("s.started_at") + ` FROM sessions s, cur WHERE s.id = cur.session) THEN r.status
+	ELSE 'unread'
+END)`
+
+// sessionNotifiedSQL is true if the current session has been notified of a
+// recipient's message, with the same fallbacks as sessionStatusSQL
+var sessionNotifiedSQL = `(CASE
+	WHEN r.to_id != (SELECT role FROM cur) THEN r.notified_at IS NOT NULL
+	WHEN EXISTS (SELECT 1 FROM session_reads sr, cur
+	             WHERE sr.message_id = r.message_id AND sr.session_id = cur.session AND sr.notified_at IS NOT NULL) THEN 1
+	WHEN ` + julianSQL("m.created_at") + ` < (SELECT ` + julianSQL("s.started_at") + ` FROM sessions s, cur WHERE s.id = cur.session) THEN r.notified_at IS NOT NULL
+	ELSE 0
+END)`
+
+// julianSQL converts a time column to a Julian day number, so times written
+// in different zones compare correctly. It reads both sqlTime values and
+// the "2006-01-02 15:04:05.999 -0700 MST" form time.Time is stored in,
+// to the second.
+func julianSQL(col string) string {
+	offset := `substr(` + col + `, 20 + instr(substr(` + col + `, 20), ' '), 5)`
+	return `(julianday(substr(` + col + `, 1, 19)) - (CAST(substr(` + offset + `, 1, 3) AS INTEGER) * 60` +
+		` + (CASE WHEN substr(` + offset + `, 1, 1) = '-' THEN -1 ELSE 1 END) * CAST(substr(` + offset + `, 4, 2) AS INTEGER)) / 1440.0)`
+}
+
+// Session is a named instance of a role, e.g. dev#1
+type Session struct {
+	ID        string
+	Role      string
+	StartedAt time.Time
+	LastSeen  time.Time
+}
+
+// UseSession makes read and notified state apply to the session id, an
+// instance of role, instead of the role as a whole. The session is
+// registered on first use and its last-seen time refreshed.
+func (db *DB) UseSession(role, id string) error {
+	now := time.Now()
+	_, err := db.conn.Exec(`
+		INSERT INTO sessions (id, role, started_at, last_seen) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET last_seen = excluded.last_seen`,
+		id, role, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
+	}
+
+	db.session = id
+	db.sessionRole = role
+	return nil
+}
+
+// Session returns the session set by UseSession, or "" if none
+func (db *DB) Session() string {
+	return db.session
+}
+
+// sessionArgs returns the arguments bound by sessionCTE
+func (db *DB) sessionArgs() []interface{} {
+	return []interface{}{db.session, db.sessionRole}
+}
+
+// markSession records that the current session read or was notified of
+// messages, where column is read_at or notified_at
+func (db *DB) markSession(column string, messageIDs []string, at time.Time) error {
+	for _, id := range messageIDs {
+		_, err := db.conn.Exec(`
+			INSERT INTO session_reads (message_id, session_id, `+column+`) VALUES (?, ?, ?)
+			ON CONFLICT(message_id, session_id) DO UPDATE SET `+column+` = COALESCE(`+column+`, excluded.`+column+`)`,
+			id, db.session, at)
+		if err != nil {
+			return fmt.Errorf("failed to update session state: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetSessions returns the sessions seen since the given time, grouped by
+// role and most recently seen first
+func (db *DB) GetSessions(since time.Time) ([]Session, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, role, started_at, last_seen FROM sessions
+		WHERE `+julianSQL("last_seen")+` >= julianday(?)
+		ORDER BY role, `+julianSQL("last_seen")+` DESC`, sqlTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.Role, &s.StartedAt, &s.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session rows: %w", err)
+	}
+	return sessions, nil
+}
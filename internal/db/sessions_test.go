@@ -0,0 +1,111 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionReadState(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Mail from before any session started keeps the role's state, even
+	// when written in a zone whose local time reads later
+	db.SendMessage(&Message{
+		ID:        "old001",
+		FromID:    "pm",
+		Subject:   "Old",
+		Body:      "Already handled",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now().Add(-time.Hour).In(time.FixedZone("IST", 5*3600+1800)),
+	}, []string{"dev"})
+	db.MarkRead("old001", "dev")
+
+	// Two sessions of dev, each on its own handle like separate processes
+	if err := db.UseSession("dev", "dev#1"); err != nil {
+		t.Fatalf("UseSession failed: %v", err)
+	}
+	other, err := Open(db.path)
+	if err != nil {
+		t.Fatalf("failed to open second handle: %v", err)
+	}
+	defer other.Close()
+	if err := other.UseSession("dev", "dev#2"); err != nil {
+		t.Fatalf("UseSession failed: %v", err)
+	}
+
+	db.SendMessage(&Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "New",
+		Body:      "For every session",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now().Add(time.Second),
+	}, []string{"dev"})
+
+	if err := db.MarkRead("msg001", "dev"); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+	if err := db.MarkNotified("msg001", "dev"); err != nil {
+		t.Fatalf("MarkNotified failed: %v", err)
+	}
+
+	if count, _ := db.CountUnread("dev"); count != 0 {
+		t.Errorf("expected dev#1 to have nothing unread, got %d", count)
+	}
+	unread, _ := other.GetInbox("dev", StatusUnread)
+	if len(unread) != 1 || unread[0].ID != "msg001" {
+		t.Fatalf("expected dev#2 to still see msg001 unread, got %+v", unread)
+	}
+	if pending, _ := other.GetUnnotified("dev"); len(pending) != 1 {
+		t.Errorf("expected dev#2 to still be notified of msg001, got %+v", pending)
+	}
+	if n, err := other.MarkAllRead("dev"); err != nil || n != 1 {
+		t.Errorf("expected dev#2 to mark one message read, got %d, %v", n, err)
+	}
+
+	// The sender sees the role's read receipt, and other roles are unaffected
+	recipients, _ := db.GetRecipientStatus("msg001")
+	if len(recipients) != 1 || recipients[0].Status != StatusRead {
+		t.Errorf("expected dev to have read msg001, got %+v", recipients)
+	}
+
+	sessions, err := db.GetSessions(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("GetSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Role != "dev" {
+		t.Errorf("expected two dev sessions, got %+v", sessions)
+	}
+}
+
+func TestGetSessionsAcrossZones(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Written by hosts in other zones: as text, the stale session sorts
+	// after the cutoff and the recent one before it
+	now := time.Now()
+	stale := now.Add(-2 * time.Hour).In(time.FixedZone("LINT", 14*3600))
+	recent := now.In(time.FixedZone("HST", -10*3600))
+	for _, s := range []struct {
+		id   string
+		seen time.Time
+	}{{"dev#1", stale}, {"dev#2", recent}} {
+		_, err := db.conn.Exec(`INSERT INTO sessions (id, role, started_at, last_seen) VALUES (?, 'dev', ?, ?)`,
+			s.id, s.seen, s.seen)
+		if err != nil {
+			t.Fatalf("failed to insert session: %v", err)
+		}
+	}
+
+	sessions, err := db.GetSessions(now.Add(-time.Minute).UTC())
+	if err != nil {
+		t.Fatalf("GetSessions failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "dev#2" {
+		t.Errorf("expected only dev#2 seen in the last minute, got %+v", sessions)
+	}
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// awakeSQL restricts a query on recipients r to rows that aren't snoozed
+const awakeSQL = `(r.snoozed_until IS NULL OR r.snoozed_until <= strftime('%Y-%m-%d %H:%M:%S', 'now'))`
+
+// SnoozedMessage is a message hidden from a recipient until a later time
+type SnoozedMessage struct {
+	InboxMessage
+	SnoozedUntil time.Time
+}
+
+// Snooze hides a message in a recipient's inbox until the given time. When
+// the snooze expires the message comes back unread and is notified again.
+// Returns false if the message is not unread or read, e.g. archived.
+func (db *DB) Snooze(messageID, toID string, until time.Time) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE recipients SET snoozed_until = ?, status = 'unread', read_at = NULL, notified_at = NULL
+		WHERE message_id = ? AND to_id = ? AND status IN ('unread', 'read')`,
+		sqlTime(until), messageID, toID)
+	if err != nil {
+		return false, fmt.Errorf("failed to snooze: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	// Every session of the role sees it again
+	_, err = tx.Exec(`
+		DELETE FROM session_reads
+		WHERE message_id = ? AND session_id IN (SELECT id FROM sessions WHERE role = ?)`,
+		messageID, toID)
+	if err != nil {
+		return false, fmt.Errorf("failed to snooze: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// Unsnooze brings a snoozed message back to a recipient's inbox now, as
+// unread. Returns false if it was not snoozed.
+func (db *DB) Unsnooze(messageID, toID string) (bool, error) {
+	result, err := db.conn.Exec(`
+		UPDATE recipients AS r SET snoozed_until = NULL
+		WHERE message_id = ? AND to_id = ? AND NOT `+awakeSQL,
+		messageID, toID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unsnooze: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// GetSnoozed returns a recipient's snoozed messages, soonest to wake first
+func (db *DB) GetSnoozed(toID string) ([]SnoozedMessage, error) {
+	rows, err := db.conn.Query(`
+		SELECT m.id, m.from_id, m.subject, m.body, `+prioritySQL+`, m.msg_type,
+		       m.thread_id, m.reply_to_id, m.created_at, r.status, r.snoozed_until
+		FROM messages m
+		JOIN recipients r ON m.id = r.message_id
+		WHERE r.to_id = ? AND NOT `+awakeSQL+`
+		ORDER BY r.snoozed_until ASC`, toID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query snoozed: %w", err)
+	}
+	defer rows.Close()
+
+	var snoozed []SnoozedMessage
+	var messageIDs []string
+	for rows.Next() {
+		var s SnoozedMessage
+		var threadID, replyToID sql.NullString
+		err := rows.Scan(
+			&s.ID, &s.FromID, &s.Subject, &s.Body, &s.Priority, &s.MsgType,
+			&threadID, &replyToID, &s.CreatedAt, &s.Status, &s.SnoozedUntil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan snoozed: %w", err)
+		}
+		if threadID.Valid {
+			s.ThreadID = &threadID.String
+		}
+		if replyToID.Valid {
+			s.ReplyToID = &replyToID.String
+		}
+		snoozed = append(snoozed, s)
+		messageIDs = append(messageIDs, s.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating snoozed rows: %w", err)
+	}
+
+	recipientMap, err := db.getRecipientsForMessages(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snoozed {
+		snoozed[i].ToIDs = recipientMap[snoozed[i].ID]
+	}
+
+	return snoozed, nil
+}
+
+// WakeSnoozed clears expired snoozes. Queries already show messages whose
+// snooze has expired, so this only affects change notifications.
+func (db *DB) WakeSnoozed() (int64, error) {
+	result, err := db.conn.Exec(`
+		UPDATE recipients SET snoozed_until = NULL
+		WHERE snoozed_until IS NOT NULL
+		  AND snoozed_until <= strftime('%Y-%m-%d %H:%M:%S', 'now')`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to wake snoozed messages: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// NextWake returns when the next snoozed message wakes up, or nil if none
+// is snoozed
+func (db *DB) NextWake() (*time.Time, error) {
+	return db.minTime(`SELECT MIN(snoozed_until) FROM recipients WHERE snoozed_until IS NOT NULL`)
+}
+
+// minTime runs a query returning a single MIN() over a UTC text time column
+func (db *DB) minTime(query string) (*time.Time, error) {
+	var next sql.NullString
+	if err := db.conn.QueryRow(query).Scan(&next); err != nil {
+		return nil, fmt.Errorf("failed to query next due time: %w", err)
+	}
+	if !next.Valid {
+		return nil, nil
+	}
+
+	t, err := time.Parse(sqlTimeFormat, next.String)
+	if err != nil {
+		return nil, fmt.Errorf("invalid due time %q: %w", next.String, err)
+	}
+	return &t, nil
+}
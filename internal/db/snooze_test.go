@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSnooze(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Later",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg, []string{"dev", "qa"})
+	db.MarkRead("msg001", "dev")
+	db.MarkNotified("msg001", "dev")
+
+	if ok, err := db.Snooze("msg001", "dev", time.Now().Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("Snooze = %v, %v", ok, err)
+	}
+
+	if inbox, _ := db.GetInbox("dev", StatusUnread, StatusRead); len(inbox) != 0 {
+		t.Errorf("snoozed message should be hidden from inbox, got %d", len(inbox))
+	}
+	if count, _ := db.CountUnread("dev"); count != 0 {
+		t.Errorf("snoozed message should not be counted, got %d", count)
+	}
+	if unnotified, _ := db.GetUnnotified("dev"); len(unnotified) != 0 {
+		t.Errorf("snoozed message should not be notified, got %d", len(unnotified))
+	}
+
+	// Other recipients are unaffected
+	if count, _ := db.CountUnread("qa"); count != 1 {
+		t.Errorf("expected qa to still have 1 unread, got %d", count)
+	}
+
+	snoozed, err := db.GetSnoozed("dev")
+	if err != nil {
+		t.Fatalf("GetSnoozed failed: %v", err)
+	}
+	if len(snoozed) != 1 || snoozed[0].ID != "msg001" {
+		t.Fatalf("expected msg001 snoozed, got %+v", snoozed)
+	}
+
+	// Expired: back as unread and due for a fresh notification
+	db.Snooze("msg001", "dev", time.Now().Add(-time.Second))
+	unnotified, _ := db.GetUnnotified("dev")
+	if len(unnotified) != 1 || unnotified[0].Status != "unread" {
+		t.Errorf("expected woken message to be unread and unnotified, got %+v", unnotified)
+	}
+	if n, _ := db.WakeSnoozed(); n != 1 {
+		t.Errorf("expected WakeSnoozed to clear 1 snooze, got %d", n)
+	}
+	if snoozed, _ := db.GetSnoozed("dev"); len(snoozed) != 0 {
+		t.Errorf("expected no snoozed messages, got %+v", snoozed)
+	}
+}
+
+func TestSnoozeArchivedAndClear(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, id := range []string{"msg001", "msg002"} {
+		db.SendMessage(&Message{
+			ID: id, FromID: "pm", Subject: "Later", Body: "Body",
+			Priority: "normal", MsgType: "message", CreatedAt: time.Now(),
+		}, []string{"dev"})
+	}
+
+	// Archived mail stays archived
+	db.Archive("msg001", "dev")
+	if ok, err := db.Snooze("msg001", "dev", time.Now().Add(time.Hour)); err != nil || ok {
+		t.Errorf("Snooze(archived) = %v, %v; want false", ok, err)
+	}
+	if archived, _ := db.GetInbox("dev", StatusArchived); len(archived) != 1 {
+		t.Errorf("expected msg001 to stay archived, got %+v", archived)
+	}
+
+	// Clearing brings it back now
+	db.Snooze("msg002", "dev", time.Now().Add(time.Hour))
+	if ok, err := db.Unsnooze("msg002", "dev"); err != nil || !ok {
+		t.Fatalf("Unsnooze = %v, %v", ok, err)
+	}
+	if count, _ := db.CountUnread("dev"); count != 1 {
+		t.Errorf("expected msg002 back unread, got count %d", count)
+	}
+	if ok, _ := db.Unsnooze("msg002", "dev"); ok {
+		t.Error("expected a second Unsnooze to report nothing cleared")
+	}
+}
+
+func TestSubscribeWakesSnoozed(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg := &Message{
+		ID:        "msg001",
+		FromID:    "pm",
+		Subject:   "Later",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	db.SendMessage(msg, []string{"dev"})
+	db.Snooze("msg001", "dev", time.Now().Add(1500*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := db.Subscribe(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for snooze to expire")
+	}
+
+	if next, _ := db.NextWake(); next != nil {
+		t.Errorf("expected snooze to be cleared, next wake still %v", next)
+	}
+	if count, _ := db.CountUnread("dev"); count != 1 {
+		t.Errorf("expected woken message to be unread, got count %d", count)
+	}
+}
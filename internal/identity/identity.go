@@ -12,14 +12,43 @@ import (
 const (
 	// EnvIdentity is the environment variable for explicit identity
 	EnvIdentity = "AMAIL_IDENTITY"
+
+	// InstanceSeparator separates a role from its instance, as in dev#1
+	InstanceSeparator = "#"
 )
 
-// Resolution represents the result of identity resolution
+// Resolution represents the result of identity resolution. Identity is the
+// role used for addressing; Instance is the full name of a role instance
+// such as dev#1, which keeps its own read state, or empty.
 type Resolution struct {
 	Identity string
+	Instance string
 	Source   string
 }
 
+// newResolution builds a Resolution for id, splitting off any instance
+func newResolution(id, source string) *Resolution {
+	res := &Resolution{Identity: RoleOf(id), Source: source}
+	if res.Identity != id {
+		res.Instance = id
+	}
+	return res
+}
+
+// RoleOf returns the role of an identity, dropping any instance suffix
+func RoleOf(id string) string {
+	role, _, _ := strings.Cut(id, InstanceSeparator)
+	return role
+}
+
+// Name returns the instance if there is one, otherwise the role
+func (r *Resolution) Name() string {
+	if r.Instance != "" {
+		return r.Instance
+	}
+	return r.Identity
+}
+
 // Resolve determines the current identity using the priority chain:
 // 1. AMAIL_IDENTITY env var
 // 2. tmux session mapping from config
@@ -27,20 +56,14 @@ type Resolution struct {
 func Resolve(cfg *config.Config) (*Resolution, error) {
 	// 1. Check environment variable
 	if id := os.Getenv(EnvIdentity); id != "" {
-		return &Resolution{
-			Identity: id,
-			Source:   "environment variable ($AMAIL_IDENTITY)",
-		}, nil
+		return newResolution(id, "environment variable ($AMAIL_IDENTITY)"), nil
 	}
 
 	// 2. Check tmux session mapping
 	if tmuxSession := getTmuxSession(); tmuxSession != "" {
 		if cfg != nil && cfg.Identity.Tmux != nil {
 			if id, ok := cfg.Identity.Tmux[tmuxSession]; ok {
-				return &Resolution{
-					Identity: id,
-					Source:   fmt.Sprintf("tmux session mapping (%s)", tmuxSession),
-				}, nil
+				return newResolution(id, fmt.Sprintf("tmux session mapping (%s)", tmuxSession)), nil
 			}
 		}
 	}
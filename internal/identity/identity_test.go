@@ -29,6 +29,19 @@ func TestResolveFromEnvVar(t *testing.T) {
 	}
 }
 
+func TestResolveInstance(t *testing.T) {
+	os.Setenv(EnvIdentity, "dev#2")
+	defer os.Unsetenv(EnvIdentity)
+
+	res, err := Resolve(config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if res.Identity != "dev" || res.Instance != "dev#2" || res.Name() != "dev#2" {
+		t.Errorf("expected instance dev#2 of dev, got %+v", res)
+	}
+}
+
 func TestResolveNoIdentity(t *testing.T) {
 	// Ensure env var is not set
 	os.Unsetenv(EnvIdentity)
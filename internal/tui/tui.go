@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -26,14 +28,33 @@ const (
 	ViewMailboxes
 )
 
+// Folder selects which messages the list view shows
+type Folder int
+
+const (
+	FolderInbox Folder = iota
+	FolderSent
+	FolderArchived
+)
+
+// SentMailbox is the switcher entry showing messages sent by the current identity
+const SentMailbox = "Sent"
+
+// ArchiveMailbox is the switcher entry showing the current identity's archive
+const ArchiveMailbox = "Archive"
+
+// snoozeDuration is how long the snooze key hides a message
+const snoozeDuration = time.Hour
+
 // Model is the main TUI model
 type Model struct {
 	db       *db.DB
 	cfg      *config.Config
 	identity string
 
-	// Current view
-	view View
+	// Current view and folder
+	view   View
+	folder Folder
 
 	// Components
 	inboxTable    table.Model
@@ -42,12 +63,18 @@ type Model struct {
 	composeBody   textarea.Model
 	help          help.Model
 
+	// Mailbox change notifications; nil disables live refresh
+	changes <-chan int64
+
 	// Data
 	messages        []db.InboxMessage
 	currentMessage  *db.InboxMessage
 	mailboxes       []string
 	selectedMailbox int
 
+	// Only inbox messages with this label are listed, if set
+	labelFilter string
+
 	// Compose state
 	composeTo      string
 	composeSubject string
@@ -113,6 +140,10 @@ type keyMap struct {
 	Reply    key.Binding
 	ReplyAll key.Binding
 	Delete   key.Binding
+	Archive  key.Binding
+	Snooze   key.Binding
+	Label    key.Binding
+	Star     key.Binding
 	MarkRead key.Binding
 	Refresh  key.Binding
 	Tab      key.Binding
@@ -131,6 +162,10 @@ var keys = keyMap{
 	Reply:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reply")),
 	ReplyAll: key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "reply all")),
 	Delete:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+	Archive:  key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "archive/unarchive")),
+	Snooze:   key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "snooze 1h")),
+	Label:    key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "filter label")),
+	Star:     key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "star")),
 	MarkRead: key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "mark read")),
 	Refresh:  key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "refresh")),
 	Tab:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch mailbox")),
@@ -151,8 +186,8 @@ func (k inboxKeyMap) ShortHelp() []key.Binding {
 func (k inboxKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{keys.Up, keys.Down, keys.Enter},
-		{keys.Compose, keys.Reply, keys.Delete},
-		{keys.MarkRead, keys.Refresh, keys.Tab},
+		{keys.Compose, keys.Reply, keys.Delete, keys.Archive, keys.Star},
+		{keys.Snooze, keys.MarkRead, keys.Label, keys.Refresh, keys.Tab},
 		{keys.Help, keys.Quit},
 	}
 }
@@ -197,20 +232,33 @@ func (k mailboxesKeyMap) FullHelp() [][]key.Binding {
 	}
 }
 
-// NewModel creates a new TUI model
-func NewModel(database *db.DB, cfg *config.Config, identity string) Model {
-	// Create inbox table
+// inboxColumns returns the message table columns for a folder
+func inboxColumns(folder Folder) []table.Column {
+	peer := "From"
+	if folder == FolderSent {
+		peer = "To"
+	}
 	columns := []table.Column{
-		{Title: "", Width: 1},
+		{Title: "", Width: 2},
 		{Title: "ID", Width: 8},
-		{Title: "From", Width: 12},
+		{Title: peer, Width: 12},
 		{Title: "Subject", Width: 30},
 		{Title: "Priority", Width: 8},
 		{Title: "Time", Width: 12},
 	}
+	if folder != FolderSent {
+		columns = append(columns,
+			table.Column{Title: "Due", Width: 10},
+			table.Column{Title: "Labels", Width: 20})
+	}
+	return columns
+}
 
+// NewModel creates a new TUI model
+func NewModel(database *db.DB, cfg *config.Config, identity string) Model {
+	// Create inbox table
 	t := table.New(
-		table.WithColumns(columns),
+		table.WithColumns(inboxColumns(FolderInbox)),
 		table.WithFocused(true),
 		table.WithHeight(10),
 	)
@@ -241,8 +289,8 @@ func NewModel(database *db.DB, cfg *config.Config, identity string) Model {
 	bodyInput.Placeholder = "Message body..."
 	bodyInput.CharLimit = 10000
 
-	// Get all mailboxes
-	mailboxes := cfg.AllRoles()
+	// Get all mailboxes, plus the sent and archive folders
+	mailboxes := append(cfg.AllRoles(), SentMailbox, ArchiveMailbox)
 
 	// Create help component
 	h := help.New()
@@ -264,9 +312,15 @@ func NewModel(database *db.DB, cfg *config.Config, identity string) Model {
 	}
 }
 
+// WatchChanges makes the TUI refresh whenever a sequence arrives on changes,
+// as returned by db.Subscribe
+func (m *Model) WatchChanges(changes <-chan int64) {
+	m.changes = changes
+}
+
 // Init initializes the model
 func (m Model) Init() tea.Cmd {
-	return m.refreshInbox()
+	return tea.Batch(m.refreshInbox(), m.waitForChange())
 }
 
 // Update handles messages
@@ -302,6 +356,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateInboxTable()
 		return m, nil
 
+	case changeMsg:
+		return m, tea.Batch(m.refreshInbox(), m.waitForChange())
+
 	case statusMsg:
 		m.statusMsg = string(msg)
 		return m, nil
@@ -346,7 +403,9 @@ func (m Model) updateInbox(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.messageView.SetContent(m.formatMessage(m.currentMessage))
 				m.messageView.GotoTop()
 				// Mark as read
-				m.db.MarkRead(m.currentMessage.ID, m.identity)
+				if m.folder == FolderInbox {
+					m.db.MarkRead(m.currentMessage.ID, m.identity)
+				}
 			}
 		}
 		return m, nil
@@ -360,18 +419,64 @@ func (m Model) updateInbox(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case key.Matches(msg, keys.Delete):
-		if len(m.messages) > 0 {
+		if m.folder != FolderSent && len(m.messages) > 0 {
+			idx := m.inboxTable.Cursor()
+			if idx < len(m.messages) {
+				msg := m.messages[idx]
+				m.db.Trash(msg.ID, m.identity)
+				m.statusMsg = "Moved " + SafeShortID(msg.ID) + " to trash"
+				return m, m.refreshInbox()
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.Snooze):
+		if m.folder == FolderInbox && len(m.messages) > 0 {
+			idx := m.inboxTable.Cursor()
+			if idx < len(m.messages) {
+				msg := m.messages[idx]
+				until := timeNow().Add(snoozeDuration)
+				m.db.Snooze(msg.ID, m.identity, until)
+				m.statusMsg = fmt.Sprintf("Snoozed %s until %s", SafeShortID(msg.ID), until.Format("15:04"))
+				return m, m.refreshInbox()
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.Archive):
+		if m.folder != FolderSent && len(m.messages) > 0 {
 			idx := m.inboxTable.Cursor()
 			if idx < len(m.messages) {
 				msg := m.messages[idx]
-				m.db.Delete(msg.ID, m.identity)
+				if m.folder == FolderArchived {
+					m.db.Unarchive(msg.ID, m.identity)
+					m.statusMsg = "Unarchived " + SafeShortID(msg.ID)
+				} else {
+					m.db.Archive(msg.ID, m.identity)
+					m.statusMsg = "Archived " + SafeShortID(msg.ID)
+				}
+				return m, m.refreshInbox()
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, keys.Star):
+		if m.folder != FolderSent && len(m.messages) > 0 {
+			idx := m.inboxTable.Cursor()
+			if idx < len(m.messages) {
+				msg := m.messages[idx]
+				if msg.StarredAt != nil {
+					m.db.Unstar(msg.ID, m.identity)
+				} else {
+					m.db.Star(msg.ID, m.identity)
+				}
 				return m, m.refreshInbox()
 			}
 		}
 		return m, nil
 
 	case key.Matches(msg, keys.MarkRead):
-		if len(m.messages) > 0 {
+		if m.folder == FolderInbox && len(m.messages) > 0 {
 			idx := m.inboxTable.Cursor()
 			if idx < len(m.messages) {
 				msg := m.messages[idx]
@@ -381,12 +486,30 @@ func (m Model) updateInbox(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case key.Matches(msg, keys.Label):
+		if m.folder != FolderSent {
+			counts, err := m.db.CountLabels(m.identity)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.labelFilter = nextLabel(m.labelFilter, counts)
+			if m.labelFilter == "" {
+				m.statusMsg = "Showing all messages"
+			} else {
+				m.statusMsg = "Showing #" + m.labelFilter
+			}
+			return m, m.refreshInbox()
+		}
+		return m, nil
+
 	case key.Matches(msg, keys.Refresh):
 		return m, m.refreshInbox()
 
 	case key.Matches(msg, keys.Tab):
+		m.labelFilter = ""
 		m.selectedMailbox = (m.selectedMailbox + 1) % len(m.mailboxes)
-		m.identity = m.mailboxes[m.selectedMailbox]
+		m.selectMailbox(m.mailboxes[m.selectedMailbox])
 		return m, m.refreshInbox()
 
 	case key.Matches(msg, keys.Help):
@@ -400,6 +523,21 @@ func (m Model) updateInbox(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// selectMailbox switches to a role's inbox, or to the sent or archive
+// folder of the current identity
+func (m *Model) selectMailbox(name string) {
+	switch name {
+	case SentMailbox:
+		m.folder = FolderSent
+	case ArchiveMailbox:
+		m.folder = FolderArchived
+	default:
+		m.identity = name
+		m.folder = FolderInbox
+	}
+	m.inboxTable.SetColumns(inboxColumns(m.folder))
+}
+
 func (m Model) updateMessage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, keys.Back):
@@ -540,6 +678,14 @@ func (m Model) viewInbox() string {
 
 	// Title with mailbox selector
 	title := fmt.Sprintf("📬 amail - %s", m.identity)
+	if m.folder == FolderSent {
+		title = fmt.Sprintf("📤 amail - %s (sent)", m.identity)
+	} else if m.folder == FolderArchived {
+		title = fmt.Sprintf("🗄  amail - %s (archive)", m.identity)
+	}
+	if m.folder != FolderSent && m.labelFilter != "" {
+		title += " #" + m.labelFilter
+	}
 	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n")
 
@@ -657,6 +803,43 @@ func (m Model) formatMessage(msg *db.InboxMessage) string {
 	b.WriteString(msg.CreatedAt.Format("2006-01-02 15:04:05"))
 	b.WriteString("\n")
 
+	if msg.DueAt != nil {
+		b.WriteString(headerStyle.Render("Due: "))
+		b.WriteString(msg.DueAt.Local().Format("2006-01-02 15:04:05"))
+		if msg.Overdue(timeNow()) {
+			b.WriteString(" (overdue)")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(msg.Attachments) > 0 {
+		names := make([]string, len(msg.Attachments))
+		for i, a := range msg.Attachments {
+			names[i] = fmt.Sprintf("%s (%s)", a.Name, formatSize(a.Size))
+		}
+		b.WriteString(headerStyle.Render("Attachments: "))
+		b.WriteString(strings.Join(names, ", "))
+		b.WriteString("\n")
+	}
+
+	if len(msg.Labels) > 0 {
+		b.WriteString(headerStyle.Render("Labels: "))
+		b.WriteString(formatLabels(msg.Labels))
+		b.WriteString("\n")
+	}
+
+	if msg.EditedAt != nil {
+		b.WriteString(headerStyle.Render("Edited: "))
+		b.WriteString(msg.EditedAt.Format("2006-01-02 15:04:05"))
+		b.WriteString("\n")
+	}
+
+	if msg.RecalledAt != nil {
+		b.WriteString(headerStyle.Render("Recalled: "))
+		b.WriteString(msg.RecalledAt.Format("2006-01-02 15:04:05"))
+		b.WriteString(" (the sender withdrew this message)\n")
+	}
+
 	b.WriteString(strings.Repeat("─", 50))
 	b.WriteString("\n\n")
 
@@ -666,11 +849,16 @@ func (m Model) formatMessage(msg *db.InboxMessage) string {
 }
 
 func (m *Model) updateInboxTable() {
+	now := timeNow()
 	rows := make([]table.Row, len(m.messages))
 	for i, msg := range m.messages {
+		// Starred messages are pinned to the top of the inbox
 		status := " "
+		if msg.StarredAt != nil {
+			status = "★"
+		}
 		if msg.Status == "unread" {
-			status = "•"
+			status += "•"
 		}
 
 		priority := msg.Priority
@@ -680,6 +868,14 @@ func (m *Model) updateInboxTable() {
 			priority = "!"
 		}
 
+		peer := msg.FromID
+		if m.folder == FolderSent {
+			peer = strings.Join(msg.ToIDs, ",")
+			if len([]rune(peer)) > 12 {
+				peer = string([]rune(peer)[:9]) + "..."
+			}
+		}
+
 		subject := msg.Subject
 		if len([]rune(subject)) > 28 {
 			subject = string([]rune(subject)[:25]) + "..."
@@ -690,11 +886,14 @@ func (m *Model) updateInboxTable() {
 		rows[i] = table.Row{
 			status,
 			SafeShortID(msg.ID),
-			msg.FromID,
+			peer,
 			subject,
 			priority,
 			timeAgo,
 		}
+		if m.folder != FolderSent {
+			rows[i] = append(rows[i], formatDue(&msg, now), formatLabels(msg.Labels))
+		}
 	}
 	m.inboxTable.SetRows(rows)
 }
@@ -707,17 +906,75 @@ type inboxMsg struct {
 
 type statusMsg string
 
+// changeMsg reports that the mailbox changed
+type changeMsg int64
+
 type errMsg struct {
 	err error
 }
 
 func (m Model) refreshInbox() tea.Cmd {
+	if m.folder == FolderSent {
+		return func() tea.Msg {
+			sent, err := m.db.GetSent(m.identity)
+			messages := make([]db.InboxMessage, len(sent))
+			for i := range sent {
+				messages[i] = sent[i].InboxMessage
+			}
+			return inboxMsg{messages: messages, err: err}
+		}
+	}
+	statuses := []string{db.StatusUnread, db.StatusRead}
+	if m.folder == FolderArchived {
+		statuses = []string{db.StatusArchived}
+	}
 	return func() tea.Msg {
-		messages, err := m.db.GetInbox(m.identity, true)
+		messages, err := m.db.GetInbox(m.identity, statuses...)
+		if m.labelFilter != "" {
+			var filtered []db.InboxMessage
+			for _, msg := range messages {
+				if msg.HasLabel(m.labelFilter) {
+					filtered = append(filtered, msg)
+				}
+			}
+			messages = filtered
+		}
+		db.SortStarredFirst(messages)
 		return inboxMsg{messages: messages, err: err}
 	}
 }
 
+// nextLabel returns the label after current in name order, cycling back to
+// no filter after the last one
+func nextLabel(current string, counts []db.LabelCount) string {
+	labels := make([]string, len(counts))
+	for i, c := range counts {
+		labels[i] = c.Label
+	}
+	sort.Strings(labels)
+
+	for _, l := range labels {
+		if current == "" || l > current {
+			return l
+		}
+	}
+	return ""
+}
+
+// waitForChange blocks until the mailbox changes
+func (m Model) waitForChange() tea.Cmd {
+	if m.changes == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		seq, ok := <-m.changes
+		if !ok {
+			return nil
+		}
+		return changeMsg(seq)
+	}
+}
+
 func (m Model) sendMessage(to, subject, body string) tea.Cmd {
 	return func() tea.Msg {
 		recipients := strings.Split(to, ",")
@@ -726,7 +983,7 @@ func (m Model) sendMessage(to, subject, body string) tea.Cmd {
 		}
 
 		msg := &db.Message{
-			ID:        generateID(),
+			ID:        db.NewID(),
 			FromID:    m.identity,
 			Subject:   subject,
 			Body:      body,
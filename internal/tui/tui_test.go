@@ -68,9 +68,10 @@ func TestNewModel(t *testing.T) {
 		t.Errorf("initial view = %v, want ViewInbox", m.view)
 	}
 
-	// AllRoles returns configured roles + reserved "user" role
-	if len(m.mailboxes) != 4 {
-		t.Errorf("mailboxes count = %d, want 4 (3 roles + user)", len(m.mailboxes))
+	// AllRoles returns configured roles + reserved "user" role, plus the sent
+	// and archive folders
+	if len(m.mailboxes) != 6 {
+		t.Errorf("mailboxes count = %d, want 6 (3 roles + user + Sent + Archive)", len(m.mailboxes))
 	}
 }
 
@@ -213,6 +214,136 @@ func TestKeyCompose(t *testing.T) {
 	}
 }
 
+func TestKeySnooze(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	database.SendMessage(&db.Message{
+		ID:        "msg1",
+		FromID:    "pm",
+		Subject:   "Later",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}, []string{"dev"})
+
+	cfg := testConfig()
+	m := NewModel(database, cfg, "dev")
+	m.view = ViewInbox
+	m.messages, _ = database.GetInbox("dev", db.StatusUnread, db.StatusRead)
+	m.updateInboxTable()
+
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}}
+	newModel, _ := m.Update(msg)
+	updated := newModel.(Model)
+
+	snoozed, _ := database.GetSnoozed("dev")
+	if len(snoozed) != 1 || snoozed[0].ID != "msg1" {
+		t.Errorf("pressing 'z' should snooze the selected message, got %+v", snoozed)
+	}
+	if !strings.Contains(updated.statusMsg, "Snoozed") {
+		t.Errorf("expected snooze status message, got %q", updated.statusMsg)
+	}
+}
+
+func TestKeyStarPinsMessage(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	for i, id := range []string{"old", "new"} {
+		database.SendMessage(&db.Message{
+			ID:        id,
+			FromID:    "pm",
+			Subject:   "Message " + id,
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		}, []string{"dev"})
+	}
+
+	cfg := testConfig()
+	m := NewModel(database, cfg, "dev")
+	m.view = ViewInbox
+	m.messages, _ = database.GetInbox("dev", db.StatusUnread, db.StatusRead)
+	m.updateInboxTable()
+	m.inboxTable.SetCursor(1) // "old"
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	updated := newModel.(Model)
+
+	result := cmd().(inboxMsg)
+	if len(result.messages) != 2 || result.messages[0].ID != "old" || result.messages[0].StarredAt == nil {
+		t.Fatalf("expected starred message pinned first, got %+v", result.messages)
+	}
+
+	// Pressing again on the pinned message unstars it
+	newModel, _ = updated.Update(result)
+	updated = newModel.(Model)
+	updated.inboxTable.SetCursor(0)
+	_, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	result = cmd().(inboxMsg)
+	if result.messages[0].ID != "new" {
+		t.Errorf("expected newest first after unstarring, got %s", result.messages[0].ID)
+	}
+}
+
+func TestKeyLabelFilter(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, id := range []string{"msg1", "msg2"} {
+		database.SendMessage(&db.Message{
+			ID:        id,
+			FromID:    "pm",
+			Subject:   "Task " + id,
+			Body:      "Body",
+			Priority:  "normal",
+			MsgType:   "message",
+			CreatedAt: time.Now(),
+		}, []string{"dev"})
+	}
+	database.UpdateLabels("msg1", "dev", []string{"blocked"}, nil)
+	database.UpdateLabels("msg2", "dev", []string{"todo"}, nil)
+
+	cfg := testConfig()
+	m := NewModel(database, cfg, "dev")
+	m.view = ViewInbox
+
+	press := func(m Model) (Model, tea.Cmd) {
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+		return newModel.(Model), cmd
+	}
+
+	// First press filters by the first label in name order
+	m, cmd := press(m)
+	if m.labelFilter != "blocked" {
+		t.Fatalf("labelFilter = %q, want blocked", m.labelFilter)
+	}
+	result := cmd().(inboxMsg)
+	if len(result.messages) != 1 || result.messages[0].ID != "msg1" {
+		t.Errorf("expected only msg1 with #blocked, got %+v", result.messages)
+	}
+	if !strings.Contains(m.View(), "#blocked") {
+		t.Error("inbox title should show the label filter")
+	}
+
+	// Then the next label, then back to everything
+	m, _ = press(m)
+	if m.labelFilter != "todo" {
+		t.Errorf("labelFilter = %q, want todo", m.labelFilter)
+	}
+	m, cmd = press(m)
+	if m.labelFilter != "" {
+		t.Errorf("labelFilter = %q, want no filter", m.labelFilter)
+	}
+	if result := cmd().(inboxMsg); len(result.messages) != 2 {
+		t.Errorf("expected both messages without a filter, got %d", len(result.messages))
+	}
+}
+
 func TestKeyBackFromCompose(t *testing.T) {
 	database, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -265,6 +396,114 @@ func TestTabSwitchesMailbox(t *testing.T) {
 	}
 }
 
+func TestTabSwitchesToSentFolder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	msg := &db.Message{
+		ID:        "sent1",
+		FromID:    "user",
+		Subject:   "From the user",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}
+	if err := database.SendMessage(msg, []string{"dev"}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	cfg := testConfig()
+	m := NewModel(database, cfg, "user")
+	m.view = ViewInbox
+	m.selectedMailbox = 3 // "user"
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	updated := newModel.(Model)
+
+	if updated.mailboxes[updated.selectedMailbox] != SentMailbox {
+		t.Fatalf("expected Sent mailbox after user, got %q", updated.mailboxes[updated.selectedMailbox])
+	}
+	if updated.folder != FolderSent {
+		t.Errorf("folder = %v, want FolderSent", updated.folder)
+	}
+	if updated.identity != "user" {
+		t.Errorf("identity = %q, want sent folder to keep %q", updated.identity, "user")
+	}
+
+	// Refresh loads messages sent by the current identity
+	result := cmd().(inboxMsg)
+	if result.err != nil {
+		t.Fatalf("refresh failed: %v", result.err)
+	}
+	if len(result.messages) != 1 || result.messages[0].ID != "sent1" {
+		t.Errorf("expected sent message, got %v", result.messages)
+	}
+
+	if !strings.Contains(updated.View(), "(sent)") {
+		t.Error("sent folder view should indicate the sent folder")
+	}
+
+	// Then the archive, then Tab wraps back to the first role's inbox
+	newModel, _ = updated.Update(tea.KeyMsg{Type: tea.KeyTab})
+	archive := newModel.(Model)
+	if archive.folder != FolderArchived || archive.identity != "user" {
+		t.Errorf("expected user's archive after Sent, got folder %v identity %q", archive.folder, archive.identity)
+	}
+	newModel, _ = archive.Update(tea.KeyMsg{Type: tea.KeyTab})
+	wrapped := newModel.(Model)
+	if wrapped.folder != FolderInbox || wrapped.identity != "dev" {
+		t.Errorf("expected dev inbox after Archive, got folder %v identity %q", wrapped.folder, wrapped.identity)
+	}
+}
+
+func TestKeyArchiveAndArchiveFolder(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	database.SendMessage(&db.Message{
+		ID:        "msg1",
+		FromID:    "pm",
+		Subject:   "Done",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}, []string{"dev"})
+
+	cfg := testConfig()
+	m := NewModel(database, cfg, "dev")
+	m.view = ViewInbox
+	m.messages, _ = database.GetInbox("dev", db.StatusUnread, db.StatusRead)
+	m.updateInboxTable()
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if result := cmd().(inboxMsg); len(result.messages) != 0 {
+		t.Errorf("archived message should leave the inbox, got %+v", result.messages)
+	}
+
+	// The archive folder lists it, and 'a' there moves it back
+	updated := newModel.(Model)
+	updated.selectMailbox(ArchiveMailbox)
+	result := updated.refreshInbox()().(inboxMsg)
+	if len(result.messages) != 1 || result.messages[0].ID != "msg1" {
+		t.Fatalf("expected msg1 in the archive, got %+v", result.messages)
+	}
+	if !strings.Contains(updated.View(), "(archive)") {
+		t.Error("archive folder view should indicate the archive")
+	}
+
+	newModel, _ = updated.Update(result)
+	updated = newModel.(Model)
+	_, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if result := cmd().(inboxMsg); len(result.messages) != 0 {
+		t.Errorf("unarchived message should leave the archive, got %+v", result.messages)
+	}
+	if inbox, _ := database.GetInbox("dev", db.StatusRead); len(inbox) != 1 {
+		t.Errorf("expected message back in the inbox as read, got %d", len(inbox))
+	}
+}
+
 func TestFormatMessage(t *testing.T) {
 	database, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -281,6 +520,9 @@ func TestFormatMessage(t *testing.T) {
 			Priority:  "high",
 			MsgType:   "message",
 			CreatedAt: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+			Attachments: []db.Attachment{
+				{Name: "build.log", Size: 2048},
+			},
 		},
 		ToIDs:  []string{"dev", "qa"},
 		Status: "unread",
@@ -303,6 +545,9 @@ func TestFormatMessage(t *testing.T) {
 	if !strings.Contains(formatted, "high") {
 		t.Error("formatted message should contain priority")
 	}
+	if !strings.Contains(formatted, "build.log (2.0 KB)") {
+		t.Error("formatted message should list attachments")
+	}
 }
 
 func TestInboxMsgUpdate(t *testing.T) {
@@ -336,6 +581,52 @@ func TestInboxMsgUpdate(t *testing.T) {
 	}
 }
 
+func TestChangeMsgRefreshesInbox(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	cfg := testConfig()
+	m := NewModel(database, cfg, "dev")
+
+	changes := make(chan int64, 1)
+	m.WatchChanges(changes)
+
+	database.SendMessage(&db.Message{
+		ID:        "msg1",
+		FromID:    "pm",
+		Subject:   "New",
+		Body:      "Body",
+		Priority:  "normal",
+		MsgType:   "message",
+		CreatedAt: time.Now(),
+	}, []string{"dev"})
+	changes <- 1
+
+	change := m.waitForChange()()
+	if _, ok := change.(changeMsg); !ok {
+		t.Fatalf("expected changeMsg, got %T", change)
+	}
+
+	_, cmd := m.Update(change)
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected a refresh and a new wait, got %T", cmd())
+	}
+
+	refreshed, ok := batch[0]().(inboxMsg)
+	if !ok {
+		t.Fatalf("expected inboxMsg from refresh")
+	}
+	if len(refreshed.messages) != 1 || refreshed.messages[0].ID != "msg1" {
+		t.Errorf("expected refresh to load msg1, got %v", refreshed.messages)
+	}
+
+	// Without a subscription nothing waits
+	if cmd := NewModel(database, cfg, "dev").waitForChange(); cmd != nil {
+		t.Error("expected no wait command without WatchChanges")
+	}
+}
+
 func TestStatusMsgUpdate(t *testing.T) {
 	database, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -1,17 +1,12 @@
 package tui
 
 import (
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
-)
 
-func generateID() string {
-	bytes := make([]byte, 8)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
-}
+	"github.com/thirteen37/amail/internal/db"
+)
 
 // SafeShortID returns the first 8 characters of an ID, or the full ID if shorter
 func SafeShortID(id string) string {
@@ -53,3 +48,53 @@ func formatTimeAgo(t time.Time) string {
 		return t.Format("Jan 2")
 	}
 }
+
+// formatDue renders a request's deadline compactly, e.g. "in 3h" or
+// "⚠ 20m late" once it is overdue
+func formatDue(msg *db.InboxMessage, now time.Time) string {
+	if msg.DueAt == nil {
+		return ""
+	}
+	if msg.Overdue(now) {
+		return "⚠ " + formatSpan(now.Sub(*msg.DueAt)) + " late"
+	}
+	if msg.DueAt.After(now) {
+		return "in " + formatSpan(msg.DueAt.Sub(now))
+	}
+	return msg.DueAt.Local().Format("Jan 2")
+}
+
+// formatSpan formats a duration as a single compact unit, e.g. "5m" or "2d"
+func formatSpan(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// formatSize formats a byte count in a human-readable way
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatLabels renders labels as chips, e.g. "#blocked #todo"
+func formatLabels(labels []string) string {
+	chips := make([]string, len(labels))
+	for i, l := range labels {
+		chips[i] = "#" + l
+	}
+	return strings.Join(chips, " ")
+}
@@ -117,25 +117,3 @@ func TestFormatTimeAgo(t *testing.T) {
 		})
 	}
 }
-
-func TestGenerateID(t *testing.T) {
-	id1 := generateID()
-	id2 := generateID()
-
-	// Check length (8 bytes = 16 hex chars)
-	if len(id1) != 16 {
-		t.Errorf("generateID() length = %d, want 16", len(id1))
-	}
-
-	// Check uniqueness
-	if id1 == id2 {
-		t.Error("generateID() should generate unique IDs")
-	}
-
-	// Check it's valid hex
-	for _, c := range id1 {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f')) {
-			t.Errorf("generateID() contains invalid hex character: %c", c)
-		}
-	}
-}